@@ -0,0 +1,560 @@
+package dcnm
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/ciscoecosystem/dcnm-go-client/models"
+)
+
+// defaultRetryStatusCodes are the HTTP status codes retried when the provider
+// configuration does not list any.
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retryPolicy decides which failed requests are sent again and how long to
+// wait in between.
+type retryPolicy struct {
+	MaxRetries  int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	StatusCodes map[int]bool
+}
+
+// backoff returns the delay before the given retry, doubling from MinBackoff
+// up to MaxBackoff.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	wait := p.MinBackoff
+	for i := 1; i < retry && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// idempotentMethods are the methods which can be sent again when it is not
+// known whether the controller processed the first request.
+var idempotentMethods = map[string]bool{
+	"GET":    true,
+	"HEAD":   true,
+	"PUT":    true,
+	"DELETE": true,
+}
+
+// retryable reports whether a request that ended with the given response and
+// error is worth sending again. Creates are only sent again when the controller
+// rejected the first one as busy or rate limited, as a lost response would
+// otherwise replay it.
+func (p retryPolicy) retryable(method string, cont *container.Container, resp *http.Response, err error) bool {
+	// connections which could not be opened never sent the request.
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	// other transport failures such as connection resets and timeouts
+	// always surface as *url.Error from the http client.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return idempotentMethods[method]
+	}
+
+	if resp == nil || resp.StatusCode == http.StatusOK {
+		return false
+	}
+
+	if idempotentMethods[method] {
+		if p.StatusCodes[resp.StatusCode] {
+			return true
+		}
+	} else {
+		rejected := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+		if rejected && p.StatusCodes[resp.StatusCode] {
+			return true
+		}
+	}
+
+	msg := ""
+	if cont != nil {
+		msg = cont.S("message").String()
+	} else if err != nil {
+		msg = err.Error()
+	}
+	return strings.Contains(strings.ToLower(msg), "busy")
+}
+
+// isCertificateError reports whether err is a failure to verify the server
+// certificate, which is not worth retrying.
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid)
+}
+
+// apiError is returned for requests which DCNM answered with an error status.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d Error : %s", e.StatusCode, e.Message)
+}
+
+// notFoundError is returned by lookups which got a valid response from DCNM
+// that did not contain the requested object.
+type notFoundError string
+
+func (e notFoundError) Error() string {
+	return string(e)
+}
+
+// isNotFound reports whether err means the requested object does not exist in
+// DCNM.
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+
+	var notFound notFoundError
+	return errors.As(err, &notFound)
+}
+
+// Client is the DCNM client handed to resources and data sources. It sends
+// the go client models to DCNM, with every request retried according to the
+// provider retry policy.
+type Client struct {
+	*clientState
+
+	// ctx cancels the requests and the retries of the client, it is set by
+	// withContext for each operation.
+	ctx context.Context
+}
+
+// clientState is shared by the client of the provider and the copies made
+// for each operation.
+type clientState struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+
+	// authMu guards the platform and the login token, which are shared by
+	// the resources running in parallel.
+	authMu sync.Mutex
+
+	// platform is the API of the controller, "dcnm" or "ndfc". It is
+	// "auto" until the first request detects it.
+	platform string
+
+	username string
+	password string
+	domain   string
+	expiry   int64
+
+	// token is a pre-issued API token, sent instead of logging in with the
+	// username and password.
+	token string
+
+	authToken  string
+	authExpiry time.Time
+
+	// tokenCache shares the login token with the other provider instances,
+	// it is nil unless configured.
+	tokenCache *tokenCache
+
+	retry    retryPolicy
+	throttle *throttle
+}
+
+// withContext returns a copy of the client whose requests and retries are
+// cancelled along with ctx. The copy shares the login and the throttle of c.
+func (c *Client) withContext(ctx context.Context) *Client {
+	return &Client{clientState: c.clientState, ctx: ctx}
+}
+
+// context returns the context of the requests, which is the background one
+// for the client of the provider.
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// authenticate logs in with the username and password, for a token valid for
+// expiry milliseconds.
+func (c *Client) authenticate() (string, error) {
+	if c.platform == platformNDFC {
+		return c.authenticateNDFC()
+	}
+
+	body := []byte(fmt.Sprintf(`{"expirationTime": %d}`, c.expiry))
+	req, err := c.newRequest("POST", "/rest/logon", body)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(c.username, c.password)
+
+	cont, resp, err := c.sendRequest(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusInternalServerError {
+		return "", fmt.Errorf("Invalid username or password")
+	}
+	if err := checkforerrors(cont, resp); err != nil {
+		return "", err
+	}
+
+	return stripQuotes(cont.S("Dcnm-Token").String()), nil
+}
+
+// canLogin reports whether the client logs in with its username and password,
+// so a rejected token can be replaced.
+func (c *Client) canLogin() bool {
+	return c.token == "" && c.username != ""
+}
+
+// loginToken returns the token obtained with the username and password,
+// logging in when there is no valid one yet. Parallel requests wait for a
+// single login and share its token.
+func (c *Client) loginToken() (string, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	// the token is renewed a bit before it expires, so it does not expire
+	// while the request is in flight.
+	valid := func() bool {
+		return c.authToken != "" && time.Until(c.authExpiry) > 3*time.Second
+	}
+	if valid() {
+		return c.authToken, nil
+	}
+
+	if c.tokenCache != nil {
+		if cached, ok := c.tokenCache.get(); ok {
+			c.authToken, c.authExpiry = cached.Token, cached.Expiry
+			if valid() {
+				return c.authToken, nil
+			}
+		}
+	}
+
+	token, err := c.authenticate()
+	if err != nil {
+		return "", err
+	}
+	c.authToken = token
+	c.authExpiry = tokenExpiry(token, time.Duration(c.expiry)*time.Millisecond)
+
+	if c.tokenCache != nil {
+		c.tokenCache.put(cachedToken{Token: c.authToken, Expiry: c.authExpiry})
+	}
+	return c.authToken, nil
+}
+
+// invalidateToken drops the token rejected by the controller, unless a
+// parallel request already replaced it.
+func (c *Client) invalidateToken(token string) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.authToken != token {
+		return
+	}
+	c.authToken = ""
+	if c.tokenCache != nil {
+		c.tokenCache.remove()
+	}
+}
+
+// setAuthHeader authenticates the request with the pre-issued token, or with
+// a token obtained from the username and password, and returns the token
+// used. Requests are sent without a token when only a client certificate is
+// configured.
+func (c *Client) setAuthHeader(req *http.Request) (string, error) {
+	token := c.token
+	if c.canLogin() {
+		var err error
+		token, err = c.loginToken()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if token == "" {
+		return "", nil
+	}
+	if c.platform == platformNDFC {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		req.Header.Set("dcnm-token", token)
+	}
+	return token, nil
+}
+
+func (c *Client) newRequest(method, path string, body []byte) (*http.Request, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(c.context(), method, c.baseURL.ResolveReference(ref).String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// sendRequest sends the request once. Bodies which are not JSON are returned
+// as the error of failed requests, and are dropped for successful ones.
+func (c *Client) sendRequest(req *http.Request) (*container.Container, *http.Response, error) {
+	c.throttle.acquire()
+	defer c.throttle.release()
+
+	log.Println("[DEBUG] HTTP Request ", req.Method, req.URL.String())
+
+	resp, err := c.httpClient.Do(req)
+	if isCertificateError(err) {
+		return nil, nil, fmt.Errorf("unable to verify the certificate of %s : %s. Set ca_cert, or the DCNM_CA_FILE environment variable, to the CA bundle which issued it", req.URL.Host, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Println("[DEBUG] HTTP Response ", resp.StatusCode, req.Method, req.URL.String())
+
+	cont, err := container.ParseJSON(data)
+	if err != nil && resp.StatusCode != http.StatusOK {
+		return nil, resp, errors.New(string(data))
+	}
+	return cont, resp, nil
+}
+
+// do sends a request to DCNM, retrying transient failures.
+func (c *Client) do(method, path string, body *container.Container) (*container.Container, *http.Response, error) {
+	var data []byte
+	if body != nil {
+		data = body.Bytes()
+	}
+	return c.doRaw(method, path, data, nil)
+}
+
+// doRaw sends the body as it is, instead of a JSON document, with the headers
+// set over the default ones.
+func (c *Client) doRaw(method, path string, body []byte, headers map[string]string) (*container.Container, *http.Response, error) {
+	for retry := 0; ; retry++ {
+		cont, resp, err := c.sendOnce(method, path, body, headers)
+
+		if retry >= c.retry.MaxRetries || !c.retry.retryable(method, cont, resp, err) {
+			return cont, resp, err
+		}
+
+		wait := c.retry.backoff(retry + 1)
+		log.Printf("[DEBUG] Retrying %s %s in %s, attempt %d of %d", method, path, wait, retry+2, c.retry.MaxRetries+1)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-c.context().Done():
+			timer.Stop()
+			return cont, resp, c.context().Err()
+		}
+	}
+}
+
+// sendOnce sends a request, logging in again once when the controller rejects
+// the token, as it may have expired or been revoked early. A fresh request is
+// built for every attempt as the body can only be read once.
+func (c *Client) sendOnce(method, path string, body []byte, headers map[string]string) (*container.Container, *http.Response, error) {
+	if err := c.detectPlatform(); err != nil {
+		return nil, nil, err
+	}
+
+	for relogin := false; ; relogin = true {
+		req, err := c.newRequest(method, c.mapPath(path), body)
+		if err != nil {
+			return nil, nil, err
+		}
+		token, err := c.setAuthHeader(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		for key, val := range headers {
+			req.Header.Set(key, val)
+		}
+
+		cont, resp, err := c.sendRequest(req)
+		if relogin || !c.canLogin() || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			return cont, resp, err
+		}
+
+		log.Printf("[DEBUG] Token rejected for %s %s, logging in again", method, path)
+		c.invalidateToken(token)
+	}
+}
+
+func (c *Client) doAndCheck(method, path string, body *container.Container) (*container.Container, error) {
+	cont, resp, err := c.do(method, path, body)
+	if err != nil {
+		return nil, responseError(resp, err)
+	}
+	return cont, checkforerrors(cont, resp)
+}
+
+func (c *Client) GetviaURL(endpoint string) (*container.Container, error) {
+	cont, resp, err := c.do("GET", endpoint, nil)
+	if err != nil {
+		return nil, responseError(resp, err)
+	}
+
+	if cont == nil {
+		return nil, errors.New("Empty response body")
+	}
+	return cont, checkforerrors(cont, resp)
+}
+
+func (c *Client) Save(endpoint string, obj models.Model) (*container.Container, error) {
+	jsonPayload, err := prepareModel(obj)
+	if err != nil {
+		return nil, err
+	}
+	return c.doAndCheck("POST", endpoint, jsonPayload)
+}
+
+func (c *Client) SaveForAttachment(endpoint string, obj models.Model) (*container.Container, error) {
+	jsonPayload, err := prepareModelList(obj)
+	if err != nil {
+		return nil, err
+	}
+	return c.doAndCheck("POST", endpoint, jsonPayload)
+}
+
+// SaveList posts the objects as a single JSON list, for the endpoints taking
+// several objects at once.
+func (c *Client) SaveList(endpoint string, objs []models.Model) (*container.Container, error) {
+	contList := container.New()
+	contList.Array()
+	for _, obj := range objs {
+		jsonPayload, err := prepareModel(obj)
+		if err != nil {
+			return nil, err
+		}
+		contList.ArrayAppend(jsonPayload.Data())
+	}
+	return c.doAndCheck("POST", endpoint, contList)
+}
+
+// UpdateCred posts the form encoded body as it is, the endpoint does not take
+// JSON.
+func (c *Client) UpdateCred(endpoint string, body []byte) (*container.Container, error) {
+	cont, resp, err := c.doRaw("POST", endpoint, body, nil)
+	if err != nil {
+		return nil, responseError(resp, err)
+	}
+	return cont, checkforerrors(cont, resp)
+}
+
+func (c *Client) GetSegID(endpoint string) (*container.Container, error) {
+	return c.doAndCheck("POST", endpoint, nil)
+}
+
+func (c *Client) Update(endpoint string, obj models.Model) (*container.Container, error) {
+	jsonPayload, err := prepareModel(obj)
+	if err != nil {
+		return nil, err
+	}
+	return c.doAndCheck("PUT", endpoint, jsonPayload)
+}
+
+func (c *Client) Delete(endpoint string) (*container.Container, error) {
+	return c.doAndCheck("DELETE", endpoint, nil)
+}
+
+func (c *Client) DeleteWithPayload(endpoint string, obj models.Model) (*container.Container, error) {
+	jsonPayload, err := prepareModelList(obj)
+	if err != nil {
+		return nil, err
+	}
+	return c.doAndCheck("DELETE", endpoint, jsonPayload)
+}
+
+func (c *Client) SaveAndDeploy(endpoint string) (*container.Container, error) {
+	return c.doAndCheck("POST", endpoint, nil)
+}
+
+func checkforerrors(cont *container.Container, resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	if cont == nil {
+		return &apiError{StatusCode: resp.StatusCode, Message: resp.Status}
+	}
+	return &apiError{StatusCode: resp.StatusCode, Message: cont.S("message").String()}
+}
+
+// responseError keeps the status code of responses whose body was not JSON,
+// which sendRequest reports as a plain error.
+func responseError(resp *http.Response, err error) error {
+	if resp == nil {
+		return err
+	}
+	return &apiError{StatusCode: resp.StatusCode, Message: err.Error()}
+}
+
+func prepareModel(obj models.Model) (*container.Container, error) {
+	con, err := obj.ToMap()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &container.Container{}
+	for key, value := range con {
+		payload.Set(value, key)
+	}
+	return payload, nil
+}
+
+func prepareModelList(obj models.Model) (*container.Container, error) {
+	jsonPayload, err := prepareModel(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	contList := container.New()
+	contList.Array()
+	contList.ArrayAppend(jsonPayload.Data())
+	return contList, nil
+}
@@ -0,0 +1,577 @@
+package dcnm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_RetryTransientFailures(t *testing.T) {
+	dcnmClient := testMockClient(t)
+
+	testMockServer.failNext(2, http.StatusServiceUnavailable, "Service Unavailable")
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+		t.Fatalf("expected request to succeed after retries, got : %s", err)
+	}
+
+	testMockServer.failNext(1, http.StatusInternalServerError, "Fabric fab1 is busy, deployment in progress")
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+		t.Fatalf("expected busy response to be retried, got : %s", err)
+	}
+}
+
+func TestClient_RetryGivesUp(t *testing.T) {
+	dcnmClient := testMockClient(t)
+
+	testMockServer.failNext(dcnmClient.retry.MaxRetries+1, http.StatusServiceUnavailable, "Service Unavailable")
+	_, err := getRemoteFabric(dcnmClient, "fab1")
+	if err == nil || !strings.HasPrefix(err.Error(), "503") {
+		t.Fatalf("expected 503 error once retries are exhausted, got : %v", err)
+	}
+
+	testMockServer.failNext(1, http.StatusBadRequest, "Invalid payload")
+	_, err = getRemoteFabric(dcnmClient, "fab1")
+	if err == nil || !strings.HasPrefix(err.Error(), "400") {
+		t.Fatalf("expected 400 error without retry, got : %v", err)
+	}
+}
+
+func TestClient_RetryNonIdempotent(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	dcnmClient.retry.StatusCodes[http.StatusInternalServerError] = true
+
+	// the controller may have processed the request before failing
+	testMockServer.failNext(1, http.StatusInternalServerError, "Internal Server Error")
+	_, err := dcnmClient.SaveAndDeploy("/rest/control/fabrics/fab1/config-save")
+	if err == nil || !strings.HasPrefix(err.Error(), "500") {
+		t.Fatalf("expected 500 error without retry, got : %v", err)
+	}
+
+	testMockServer.failNext(1, http.StatusServiceUnavailable, "Service Unavailable")
+	if _, err := dcnmClient.SaveAndDeploy("/rest/control/fabrics/fab1/config-save"); err != nil {
+		t.Fatalf("expected rejected request to succeed after retries, got : %s", err)
+	}
+
+	testMockServer.failNext(1, http.StatusInternalServerError, "Fabric fab1 is busy, deployment in progress")
+	if _, err := dcnmClient.SaveAndDeploy("/rest/control/fabrics/fab1/config-save"); err != nil {
+		t.Fatalf("expected busy POST to succeed after retries, got : %s", err)
+	}
+
+	// updates and deletes can be sent twice without creating anything
+	testMockServer.failNext(1, http.StatusInternalServerError, "Internal Server Error")
+	if _, err := dcnmClient.doAndCheck("PUT", "/rest/interface", nil); err != nil {
+		t.Fatalf("expected PUT to succeed after retries, got : %s", err)
+	}
+	testMockServer.failNext(1, http.StatusInternalServerError, "Internal Server Error")
+	if _, err := dcnmClient.Delete("/rest/interface"); err != nil {
+		t.Fatalf("expected DELETE to succeed after retries, got : %s", err)
+	}
+
+	reset := &url.Error{Op: "Post", URL: testMockURL, Err: errors.New("connection reset by peer")}
+	if dcnmClient.retry.retryable("POST", nil, nil, reset) {
+		t.Fatalf("expected POST not to be retried after a connection reset")
+	}
+	if !dcnmClient.retry.retryable("GET", nil, nil, reset) {
+		t.Fatalf("expected GET to be retried after a connection reset")
+	}
+	if !dcnmClient.retry.retryable("DELETE", nil, nil, reset) {
+		t.Fatalf("expected DELETE to be retried after a connection reset")
+	}
+	refused := &url.Error{Op: "Post", URL: testMockURL, Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	if !dcnmClient.retry.retryable("POST", nil, nil, refused) {
+		t.Fatalf("expected POST to be retried when the connection was refused")
+	}
+}
+
+func TestClient_RetryCancelled(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	dcnmClient.retry.MinBackoff = time.Hour
+	dcnmClient.retry.MaxBackoff = time.Hour
+
+	testMockServer.failNext(1, http.StatusServiceUnavailable, "Service Unavailable")
+	defer testMockServer.failNext(0, 0, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := getRemoteFabric(dcnmClient.withContext(ctx), "fab1")
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("expected the retry to be cancelled, got : %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("expected the retry to stop with the context, took %s", elapsed)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := retryPolicy{
+		MinBackoff: 2 * time.Second,
+		MaxBackoff: 10 * time.Second,
+	}
+
+	expected := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, wait := range expected {
+		if got := p.backoff(i + 1); got != wait {
+			t.Fatalf("retry %d : expected backoff %s, got %s", i+1, wait, got)
+		}
+	}
+}
+
+func TestClient_TokenAuth(t *testing.T) {
+	testMockStart()
+	dcnmClient := testMockConfigClient(t, Config{
+		URL:   testMockURL,
+		Token: mockDCNMToken,
+	})
+
+	logons := testMockServer.callCount("POST", "/rest/logon")
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if got := testMockServer.callCount("POST", "/rest/logon") - logons; got != 0 {
+		t.Fatalf("expected no logon with a token, got %d", got)
+	}
+
+	dcnmClient = testMockConfigClient(t, Config{
+		URL:   testMockURL,
+		Token: "revoked-token",
+	})
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err == nil || !strings.HasPrefix(err.Error(), "401") {
+		t.Fatalf("expected 401 error with an invalid token, got : %v", err)
+	}
+}
+
+func TestClient_CertAuth(t *testing.T) {
+	testMockStart()
+
+	server := httptest.NewUnstartedServer(testMockServer)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	clientCert, clientKey := testClientCert(t)
+
+	dcnmClient := testMockConfigClient(t, Config{
+		URL:        server.URL,
+		CACert:     string(caCert),
+		ClientCert: string(clientCert),
+		ClientKey:  string(clientKey),
+	})
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+
+	// the server certificate is verified against ca_cert.
+	otherCA, _ := testClientCert(t)
+	dcnmClient = testMockConfigClient(t, Config{
+		URL:        server.URL,
+		CACert:     string(otherCA),
+		ClientCert: string(clientCert),
+		ClientKey:  string(clientKey),
+	})
+	dcnmClient.retry.MaxRetries = 0
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("expected certificate error, got : %v", err)
+	}
+}
+
+func TestConfig_Valid(t *testing.T) {
+	valid := []Config{
+		{URL: "https://dcnm", Username: "admin", Password: "admin"},
+		{URL: "https://dcnm", Token: "token"},
+		{URL: "https://dcnm", ClientCert: "cert.pem", ClientKey: "key.pem"},
+	}
+	for _, config := range valid {
+		if err := config.Valid(); err != nil {
+			t.Fatalf("expected %+v to be valid, got : %s", config, err)
+		}
+	}
+
+	invalid := []Config{
+		{URL: "https://dcnm"},
+		{URL: "https://dcnm", Username: "admin"},
+		{URL: "https://dcnm", Token: "token", ClientCert: "cert.pem"},
+		{Token: "token"},
+	}
+	for _, config := range invalid {
+		if err := config.Valid(); err == nil {
+			t.Fatalf("expected %+v to be invalid", config)
+		}
+	}
+}
+
+// testClientCert returns a self-signed certificate and its key, PEM encoded.
+func testClientCert(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestClient_NDFC(t *testing.T) {
+	ndfc := newMockNDFC()
+	server := httptest.NewServer(ndfc)
+	defer server.Close()
+
+	dcnmClient := testMockConfigClient(t, Config{
+		URL:      server.URL,
+		Platform: platformAuto,
+		Username: "admin",
+		Password: "admin",
+		Domain:   "DefaultAuth",
+		Expiry:   900000,
+	})
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if dcnmClient.platform != platformNDFC {
+		t.Fatalf("expected platform %s, got %s", platformNDFC, dcnmClient.platform)
+	}
+	if got := ndfc.callCount("POST", "/login"); got != 1 {
+		t.Fatalf("expected one Nexus Dashboard login, got %d", got)
+	}
+
+	r := resourceDCNMRest()
+	serial := testMockSerial(t, dcnmClient, "leaf1")
+	testMockApply(t, r, nil, map[string]interface{}{
+		"path":    "/rest/control/switches/roles",
+		"method":  "POST",
+		"payload": `[{"serialNumber": "` + serial + `", "role": "border"}]`,
+	}, dcnmClient)
+	if role, _ := getSwitchRole(dcnmClient, serial); role != "border" {
+		t.Fatalf("Bad switch role %s", role)
+	}
+
+	// the switches are deployed through the NDFC paths as well.
+	state := testMockApply(t, resourceDCNMInventroy(), nil, map[string]interface{}{
+		"fabric_name": "fab1",
+		"ip":          "10.0.0.20",
+		"username":    "admin",
+		"password":    "admin",
+	}, dcnmClient)
+	testMockCheckAttr(t, state, "deploy", "true")
+	if got := ndfc.callCount("POST", "/rest/control/fabrics/fab1/config-save"); got != 1 {
+		t.Fatalf("expected the fabric configuration to be saved once, got %d", got)
+	}
+
+	// the DCNM mock does not know the NDFC version.
+	testMockStart()
+	dcnmClient = testMockConfigClient(t, Config{
+		URL:      testMockURL,
+		Platform: platformAuto,
+		Username: "admin",
+		Password: "admin",
+		Expiry:   900000,
+	})
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if dcnmClient.platform != platformDCNM {
+		t.Fatalf("expected platform %s, got %s", platformDCNM, dcnmClient.platform)
+	}
+}
+
+func TestClient_MapPath(t *testing.T) {
+	dcnmClient := &Client{clientState: &clientState{platform: platformNDFC}}
+
+	paths := map[string]string{
+		"/rest/top-down/fabrics/fab1/vrfs":                                      "/appcenter/cisco/ndfc/api/v1/lan-fabric/rest/top-down/fabrics/fab1/vrfs",
+		"/fm/fmrest/lanConfig/saveSwitchCredentials":                            "/appcenter/cisco/ndfc/api/v1/lan-fabric/rest/lanConfig/saveSwitchCredentials",
+		"rest/control/fabrics/fab1/config-deploy":                               "/appcenter/cisco/ndfc/api/v1/lan-fabric/rest/control/fabrics/fab1/config-deploy",
+		"/appcenter/cisco/ndfc/api/v1/imagemanagement/rest/policymgnt/policies": "/appcenter/cisco/ndfc/api/v1/imagemanagement/rest/policymgnt/policies",
+	}
+	for path, expected := range paths {
+		if got := dcnmClient.mapPath(path); got != expected {
+			t.Fatalf("expected %s to be mapped to %s, got %s", path, expected, got)
+		}
+	}
+
+	dcnmClient.platform = platformDCNM
+	if got := dcnmClient.mapPath("/rest/top-down/fabrics/fab1/vrfs"); got != "/rest/top-down/fabrics/fab1/vrfs" {
+		t.Fatalf("expected DCNM path to be left as it is, got %s", got)
+	}
+}
+
+func TestClient_UntrustedCert(t *testing.T) {
+	testMockStart()
+
+	server := httptest.NewTLSServer(testMockServer)
+	defer server.Close()
+
+	dcnmClient := testMockConfigClient(t, Config{
+		URL:   server.URL,
+		Token: mockDCNMToken,
+	})
+	_, err := getRemoteFabric(dcnmClient, "fab1")
+	if err == nil || !strings.Contains(err.Error(), "Set ca_cert") {
+		t.Fatalf("expected untrusted certificate error, got : %v", err)
+	}
+
+	dcnmClient = testMockConfigClient(t, Config{
+		URL:        server.URL,
+		Token:      mockDCNMToken,
+		IsInsecure: true,
+	})
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+}
+
+func TestClient_ParallelLogin(t *testing.T) {
+	testMockStart()
+	dcnmClient := testMockClient(t)
+
+	logons := testMockServer.callCount("POST", "/rest/logon")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := getRemoteFabric(dcnmClient, "fab1")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("err : %s", err)
+		}
+	}
+	if got := testMockServer.callCount("POST", "/rest/logon") - logons; got != 1 {
+		t.Fatalf("expected a single logon for parallel requests, got %d", got)
+	}
+}
+
+func TestClient_ReloginOnUnauthorized(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	dcnmClient.retry.MaxRetries = 0
+
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	logons := testMockServer.callCount("POST", "/rest/logon")
+
+	testMockServer.revokeTokens()
+	if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+		t.Fatalf("expected request to succeed after logging in again, got : %s", err)
+	}
+	if got := testMockServer.callCount("POST", "/rest/logon") - logons; got != 1 {
+		t.Fatalf("expected one logon after the token was rejected, got %d", got)
+	}
+}
+
+func TestClient_ConcurrencyCap(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprint(w, "{}")
+	}))
+	defer server.Close()
+
+	dcnmClient := testMockConfigClient(t, Config{
+		URL:                   server.URL,
+		Token:                 mockDCNMToken,
+		MaxConcurrentRequests: 3,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := dcnmClient.GetviaURL("/rest/control/fabrics"); err != nil {
+				t.Errorf("err : %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight != 3 {
+		t.Fatalf("expected at most 3 requests in flight, got %d", maxInFlight)
+	}
+}
+
+func TestClient_RequestsPerSecond(t *testing.T) {
+	testMockStart()
+	dcnmClient := testMockConfigClient(t, Config{
+		URL:               testMockURL,
+		Token:             mockDCNMToken,
+		RequestsPerSecond: 50,
+	})
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if _, err := getRemoteFabric(dcnmClient, "fab1"); err != nil {
+			t.Fatalf("err : %s", err)
+		}
+	}
+
+	// the first request goes out at once, the 9 others 20ms apart.
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Fatalf("expected 10 requests at 50 per second to take at least 180ms, took %s", elapsed)
+	}
+}
+
+func TestClient_TokenCache(t *testing.T) {
+	testMockStart()
+
+	dir, err := ioutil.TempDir("", "dcnm-token-cache")
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := Config{
+		URL:        testMockURL,
+		Username:   "admin",
+		Password:   "admin",
+		Expiry:     900000,
+		TokenCache: filepath.Join(dir, "tokens.json"),
+	}
+
+	logons := testMockServer.callCount("POST", "/rest/logon")
+	for i := 0; i < 3; i++ {
+		if _, err := getRemoteFabric(testMockConfigClient(t, config), "fab1"); err != nil {
+			t.Fatalf("err : %s", err)
+		}
+	}
+	if got := testMockServer.callCount("POST", "/rest/logon") - logons; got != 1 {
+		t.Fatalf("expected provider instances to share one logon, got %d", got)
+	}
+
+	data, err := ioutil.ReadFile(config.TokenCache)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if strings.Contains(string(data), config.Password) {
+		t.Fatalf("token cache must not contain the password : %s", data)
+	}
+
+	// other credentials do not share the token.
+	config.Username = "operator"
+	if _, err := getRemoteFabric(testMockConfigClient(t, config), "fab1"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if got := testMockServer.callCount("POST", "/rest/logon") - logons; got != 2 {
+		t.Fatalf("expected another logon for other credentials, got %d", got)
+	}
+}
+
+func TestTokenCache_Lock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dcnm-token-cache")
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tokens.json")
+	tc := newTokenCache(path, Config{URL: "https://dcnm1"})
+
+	// another provider instance holds the lock, the token is only written
+	// once it is released.
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		tc.put(cachedToken{Token: "token1", Expiry: time.Now().Add(time.Hour)})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("expected the token cache to wait for the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, ok := tc.get(); ok {
+		t.Fatalf("expected no token to be written while the lock is held")
+	}
+
+	unlock()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the token to be written once the lock is released")
+	}
+	if token, ok := tc.get(); !ok || token.Token != "token1" {
+		t.Fatalf("expected token1 to be cached, got : %v", token)
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Now().Add(20 * time.Minute).Truncate(time.Second)
+	claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"admin","exp":%d}`, exp.Unix())))
+	if got := tokenExpiry("header."+claims+".signature", time.Minute); !got.Equal(exp) {
+		t.Fatalf("expected JWT expiry %s, got %s", exp, got)
+	}
+
+	if got := tokenExpiry("opaque-token", time.Minute); time.Until(got) > time.Minute || time.Until(got) < 50*time.Second {
+		t.Fatalf("expected fallback expiry in a minute, got %s", got)
+	}
+}
@@ -0,0 +1,130 @@
+package dcnm
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func datasourceDCNMFabric() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceDCNMFabricRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"fabric_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"template": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"bgp_asn": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"replication_mode": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"underlay_routing": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"loopback0_ip_range": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"loopback1_ip_range": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"subnet_range": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"anycast_rp_ip_range": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"multicast_group_subnet": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"anycast_gw_mac": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"fabric_mtu": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"nv_pairs": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func datasourceDCNMFabricRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ")
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	name := d.Get("name").(string)
+
+	cont, err := getRemoteFabric(dcnmClient, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set("name", stripQuotes(cont.S("fabricName").String()))
+	d.Set("template", stripQuotes(cont.S("templateName").String()))
+	if id, err := strconv.Atoi(stripQuotes(cont.S("id").String())); err == nil {
+		d.Set("fabric_id", id)
+	}
+
+	nvPairs := cont.S("nvPairs")
+	for attr, key := range fabricAttributeKeys {
+		if nvPairs.Exists(key) {
+			d.Set(attr, stripQuotes(nvPairs.S(key).String()))
+		}
+	}
+
+	nvGet := make(map[string]interface{})
+	if nvMap, ok := nvPairs.Data().(map[string]interface{}); ok {
+		for key := range nvMap {
+			nvGet[key] = stripQuotes(nvPairs.S(key).String())
+		}
+	}
+	d.Set("nv_pairs", nvGet)
+
+	d.SetId(stripQuotes(cont.S("fabricName").String()))
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
@@ -1,18 +1,18 @@
 package dcnm
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"strings"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func datasourceDCNMInterface() *schema.Resource {
 	return &schema.Resource{
-		Read: datasourceDCNMInterfaceRead,
+		ReadContext: datasourceDCNMInterfaceRead,
 
 		Schema: map[string]*schema.Schema{
 			"serial_number": &schema.Schema{
@@ -34,6 +34,9 @@ func datasourceDCNMInterface() *schema.Resource {
 					"loopback",
 					"sub-interface",
 					"ethernet",
+					"svi",
+					"nve",
+					"fex",
 				}, false),
 			},
 
@@ -280,6 +283,24 @@ func datasourceDCNMInterface() *schema.Resource {
 				Computed: true,
 			},
 
+			"nve_source_interface": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"fex_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"nv_pairs": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
 			"deploy": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -289,10 +310,10 @@ func datasourceDCNMInterface() *schema.Resource {
 	}
 }
 
-func datasourceDCNMInterfaceRead(d *schema.ResourceData, m interface{}) error {
+func datasourceDCNMInterfaceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Read method ")
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	var serialNum1 string
 	var serialNum2 string
@@ -302,7 +323,7 @@ func datasourceDCNMInterfaceRead(d *schema.ResourceData, m interface{}) error {
 	if intfType == "vpc" {
 		vpcSerialNums := strings.Split(serialNum, "~")
 		if len(vpcSerialNums) != 2 {
-			return fmt.Errorf("serial number is not valid for vpc interface")
+			return diag.Errorf("serial number is not valid for vpc interface")
 		}
 		serialNum1 = vpcSerialNums[0]
 		serialNum2 = vpcSerialNums[1]
@@ -312,11 +333,20 @@ func datasourceDCNMInterfaceRead(d *schema.ResourceData, m interface{}) error {
 
 	cont, err := getRemoteInterface(dcnmClient, serialNum1, name)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	setInterfaceAttributes(d, cont.Index(0), intfType)
 
+	nvPairs := cont.Index(0).S("interfaces").Index(0).S("nvPairs")
+	nvGet := make(map[string]interface{})
+	if nvMap, ok := nvPairs.Data().(map[string]interface{}); ok {
+		for key := range nvMap {
+			nvGet[key] = stripQuotes(nvPairs.S(key).String())
+		}
+	}
+	d.Set("nv_pairs", nvGet)
+
 	d.SetId(name)
 
 	fabName := d.Get("fabric_name").(string)
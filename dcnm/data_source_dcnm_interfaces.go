@@ -0,0 +1,169 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// interfaceTypes maps the interface types of DCNM to the ones of the provider.
+var interfaceTypes = map[string]string{
+	"INTERFACE_ETHERNET":     "ethernet",
+	"INTERFACE_PORT_CHANNEL": "port-channel",
+	"INTERFACE_VPC":          "vpc",
+	"INTERFACE_LOOPBACK":     "loopback",
+	"SUBINTERFACE":           "sub-interface",
+	"INTERFACE_VLAN":         "svi",
+	"INTERFACE_NVE":          "nve",
+}
+
+func datasourceDCNMInterfaces() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceDCNMInterfacesRead,
+
+		Schema: map[string]*schema.Schema{
+			"serial_number": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"name_regex": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+
+			"type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"port-channel",
+					"vpc",
+					"loopback",
+					"sub-interface",
+					"ethernet",
+					"svi",
+					"nve",
+				}, false),
+			},
+
+			"interfaces": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"serial_number": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"fabric_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"admin_status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"oper_status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"compliance_status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getRemoteInterfaces(client *Client, serialNum string) (*container.Container, error) {
+	durl := fmt.Sprintf("/rest/interface/detail?serialNumber=%s", serialNum)
+	return client.GetviaURL(durl)
+}
+
+func datasourceDCNMInterfacesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ")
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	serialNum := d.Get("serial_number").(string)
+	nameRegex := nameFilter(d)
+	intfType := d.Get("type").(string)
+
+	cont, err := getRemoteInterfaces(dcnmClient, serialNum)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	interfaces := make([]interface{}, 0, 1)
+	for i := 0; i < len(cont.Data().([]interface{})); i++ {
+		intfCont := cont.Index(i)
+
+		// fields which DCNM leaves out of the detail are reported empty.
+		get := func(key string) string {
+			if !intfCont.Exists(key) {
+				return ""
+			}
+			return stripQuotes(intfCont.S(key).String())
+		}
+
+		name := get("ifName")
+		if !nameRegex.MatchString(name) {
+			continue
+		}
+
+		// types the provider does not manage are reported as DCNM names them.
+		typeGet := get("ifType")
+		if mapped, ok := interfaceTypes[typeGet]; ok {
+			typeGet = mapped
+		}
+		if intfType != "" && typeGet != intfType {
+			continue
+		}
+
+		interfaces = append(interfaces, map[string]interface{}{
+			"name":              name,
+			"type":              typeGet,
+			"serial_number":     get("serialNo"),
+			"fabric_name":       get("fabricName"),
+			"description":       get("alias"),
+			"admin_status":      strings.ToLower(get("adminStatusStr")),
+			"oper_status":       strings.ToLower(get("operStatusStr")),
+			"compliance_status": get("complianceStatus"),
+		})
+	}
+	d.Set("interfaces", interfaces)
+
+	d.SetId(serialNum)
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
@@ -1,17 +1,18 @@
 package dcnm
 
 import (
+	"context"
 	"fmt"
 	"log"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func datasourceDCNMInventory() *schema.Resource {
 	return &schema.Resource{
-		Read: datasourceDCNMInventoryRead,
+		ReadContext: datasourceDCNMInventoryRead,
 
 		Schema: map[string]*schema.Schema{
 			"fabric_name": &schema.Schema{
@@ -69,7 +70,7 @@ func datasourceDCNMInventory() *schema.Resource {
 	}
 }
 
-func getRemoteSwitchforDS(dcnmClient *client.Client, fabric, name string) (*container.Container, error) {
+func getRemoteSwitchforDS(dcnmClient *Client, fabric, name string) (*container.Container, error) {
 	durl := fmt.Sprintf("/rest/control/fabrics/%s/inventory", fabric)
 
 	cont, err := dcnmClient.GetviaURL(durl)
@@ -88,10 +89,10 @@ func getRemoteSwitchforDS(dcnmClient *client.Client, fabric, name string) (*cont
 	return nil, fmt.Errorf("Desired switch not found")
 }
 
-func datasourceDCNMInventoryRead(d *schema.ResourceData, m interface{}) error {
+func datasourceDCNMInventoryRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Read method ")
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	name := d.Get("switch_name").(string)
 
@@ -99,14 +100,14 @@ func datasourceDCNMInventoryRead(d *schema.ResourceData, m interface{}) error {
 
 	cont, err := getRemoteSwitchforDS(dcnmClient, fabricName, name)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	setSwitchAttributes(d, cont)
 
 	flag, err := checkDeploy(dcnmClient, fabricName, d.Get("serial_number").(string))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if flag {
 		d.Set("deploy", true)
@@ -1,16 +1,17 @@
 package dcnm
 
 import (
+	"context"
 	"fmt"
 	"log"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func datasourceDCNMNetwork() *schema.Resource {
 	return &schema.Resource{
-		Read: datasourceDCNMNetworkRead,
+		ReadContext: datasourceDCNMNetworkRead,
 
 		Schema: map[string]*schema.Schema{
 			"fabric_name": &schema.Schema{
@@ -260,17 +261,17 @@ func datasourceDCNMNetwork() *schema.Resource {
 	}
 }
 
-func datasourceDCNMNetworkRead(d *schema.ResourceData, m interface{}) error {
+func datasourceDCNMNetworkRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Read method ")
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	name := d.Get("name").(string)
 	fabricName := d.Get("fabric_name").(string)
 
 	cont, err := getRemoteNetwork(dcnmClient, fabricName, name)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	setNetworkAttributes(d, cont)
@@ -278,7 +279,7 @@ func datasourceDCNMNetworkRead(d *schema.ResourceData, m interface{}) error {
 	deployed, err := checkNetworkDeploy(dcnmClient, fabricName, name)
 	if err != nil {
 		d.Set("deploy", false)
-		return err
+		return diag.FromErr(err)
 	}
 	d.Set("deploy", deployed)
 
@@ -293,7 +294,7 @@ func datasourceDCNMNetworkRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func getNetworkAttachmentList(client *client.Client, fabric, network string) ([]map[string]interface{}, error) {
+func getNetworkAttachmentList(client *Client, fabric, network string) ([]map[string]interface{}, error) {
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s/attachments", fabric, network)
 	cont, err := client.GetviaURL(durl)
 	if err != nil {
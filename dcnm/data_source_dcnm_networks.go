@@ -0,0 +1,129 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func datasourceDCNMNetworks() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceDCNMNetworksRead,
+
+		Schema: map[string]*schema.Schema{
+			"fabric_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"name_regex": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+
+			"networks": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"network_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"vrf_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"template": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"vlan_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"ipv4_gateway": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getRemoteNetworks(client *Client, fabric string) (*container.Container, error) {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks", fabric)
+	return client.GetviaURL(durl)
+}
+
+func datasourceDCNMNetworksRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ")
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+	nameRegex := nameFilter(d)
+
+	cont, err := getRemoteNetworks(dcnmClient, fabricName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	networks := make([]interface{}, 0, 1)
+	for i := 0; i < len(cont.Data().([]interface{})); i++ {
+		netCont := cont.Index(i)
+
+		name := stripQuotes(netCont.S("networkName").String())
+		if !nameRegex.MatchString(name) {
+			continue
+		}
+
+		netMap := map[string]interface{}{
+			"name":       name,
+			"network_id": stripQuotes(netCont.S("networkId").String()),
+			"vrf_name":   stripQuotes(netCont.S("vrf").String()),
+			"template":   stripQuotes(netCont.S("networkTemplate").String()),
+		}
+		if config, err := cleanJsonString(stripQuotes(netCont.S("networkTemplateConfig").String())); err == nil {
+			if vlan, err := strconv.Atoi(stripQuotes(config.S("vlanId").String())); err == nil {
+				netMap["vlan_id"] = vlan
+			}
+			if config.Exists("gatewayIpAddress") {
+				netMap["ipv4_gateway"] = stripQuotes(config.S("gatewayIpAddress").String())
+			}
+			if config.Exists("intfDescription") {
+				netMap["description"] = stripQuotes(config.S("intfDescription").String())
+			}
+		}
+		networks = append(networks, netMap)
+	}
+	d.Set("networks", networks)
+
+	d.SetId(fabricName)
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
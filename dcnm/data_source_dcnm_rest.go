@@ -0,0 +1,154 @@
+package dcnm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var jsonPointerRegex = regexp.MustCompile(`^(/[^/]*)*$`)
+
+func datasourceDCNMRest() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceDCNMRestRead,
+
+		Schema: map[string]*schema.Schema{
+			"path": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"filter": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(jsonPointerRegex, "must be a JSON pointer, such as \"/0/nvPairs/BGP_AS\""),
+			},
+
+			"response": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"result": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"flattened": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func datasourceDCNMRestRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	path := d.Get("path").(string)
+	cont, err := dcnmClient.GetviaURL(path)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("response", cont.String())
+
+	result, err := filterJSON(cont.Data(), d.Get("filter").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("result", string(resultJSON))
+
+	flattened := make(map[string]interface{})
+	flattenJSON(flattened, "", result)
+	d.Set("flattened", flattened)
+
+	d.SetId(path)
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
+
+// filterJSON returns the value of data found at the JSON pointer filter, as
+// defined in RFC 6901.
+func filterJSON(data interface{}, filter string) (interface{}, error) {
+	if filter == "" {
+		return data, nil
+	}
+
+	for _, token := range strings.Split(filter, "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch val := data.(type) {
+		case map[string]interface{}:
+			next, ok := val[token]
+			if !ok {
+				return nil, fmt.Errorf("key %q of filter %s not found in the response", token, filter)
+			}
+			data = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(val) {
+				return nil, fmt.Errorf("index %q of filter %s not found in the response", token, filter)
+			}
+			data = val[index]
+		default:
+			return nil, fmt.Errorf("filter %s goes past a value of the response at %q", filter, token)
+		}
+	}
+	return data, nil
+}
+
+// flattenJSON sets every scalar of data in flattened, under its dot separated
+// path. Lists are indexed by position, a scalar data is set under "value".
+func flattenJSON(flattened map[string]interface{}, path string, data interface{}) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch val := data.(type) {
+	case map[string]interface{}:
+		for k, v := range val {
+			flattenJSON(flattened, join(k), v)
+		}
+		return
+	case []interface{}:
+		for i, v := range val {
+			flattenJSON(flattened, join(strconv.Itoa(i)), v)
+		}
+		return
+	}
+
+	if path == "" {
+		path = "value"
+	}
+	switch val := data.(type) {
+	case nil:
+		flattened[path] = ""
+	case string:
+		flattened[path] = val
+	default:
+		scalar, _ := json.Marshal(val)
+		flattened[path] = string(scalar)
+	}
+}
@@ -0,0 +1,151 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func datasourceDCNMSwitches() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceDCNMSwitchesRead,
+
+		Schema: map[string]*schema.Schema{
+			"fabric_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"name_regex": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+
+			"role": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"model": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"switches": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"switch_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"serial_number": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"switch_db_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"role": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"model": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"mode": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getRemoteSwitches(dcnmClient *Client, fabric string) (*container.Container, error) {
+	durl := fmt.Sprintf("/rest/control/fabrics/%s/inventory", fabric)
+	return dcnmClient.GetviaURL(durl)
+}
+
+func datasourceDCNMSwitchesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ")
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+	nameRegex := nameFilter(d)
+	role := d.Get("role").(string)
+	model := d.Get("model").(string)
+
+	cont, err := getRemoteSwitches(dcnmClient, fabricName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	switches := make([]interface{}, 0, 1)
+	for i := 0; i < len(cont.Data().([]interface{})); i++ {
+		switchCont := cont.Index(i)
+
+		name := stripQuotes(switchCont.S("logicalName").String())
+		if !nameRegex.MatchString(name) {
+			continue
+		}
+		if model != "" && stripQuotes(switchCont.S("model").String()) != model {
+			continue
+		}
+
+		// the inventory only holds the role of the switches which were
+		// given one, the others are looked up.
+		serial := stripQuotes(switchCont.S("serialNumber").String())
+		switchRole := ""
+		if switchCont.Exists("switchRole") {
+			switchRole = stripQuotes(switchCont.S("switchRole").String())
+		}
+		if switchRole == "" || switchRole == "null" {
+			switchRole, err = getSwitchRole(dcnmClient, serial)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		if role != "" && switchRole != role {
+			continue
+		}
+
+		switches = append(switches, map[string]interface{}{
+			"switch_name":   name,
+			"ip":            stripQuotes(switchCont.S("ipAddress").String()),
+			"serial_number": serial,
+			"switch_db_id":  stripQuotes(switchCont.S("switchDbID").String()),
+			"role":          switchRole,
+			"model":         stripQuotes(switchCont.S("model").String()),
+			"mode":          stripQuotes(switchCont.S("mode").String()),
+		})
+	}
+	d.Set("switches", switches)
+
+	d.SetId(fabricName)
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
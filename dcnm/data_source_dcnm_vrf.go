@@ -1,16 +1,17 @@
 package dcnm
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func datasourceDCNMVRF() *schema.Resource {
 	return &schema.Resource{
-		Read: datasourceDCNMVRFRead,
+		ReadContext: datasourceDCNMVRFRead,
 
 		Schema: map[string]*schema.Schema{
 			"fabric_name": &schema.Schema{
@@ -203,8 +204,8 @@ func datasourceDCNMVRF() *schema.Resource {
 	}
 }
 
-func datasourceDCNMVRFRead(d *schema.ResourceData, m interface{}) error {
-	dcnmClient := m.(*client.Client)
+func datasourceDCNMVRFRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	dn := d.Get("name").(string)
 
@@ -212,14 +213,14 @@ func datasourceDCNMVRFRead(d *schema.ResourceData, m interface{}) error {
 
 	cont, err := getRemoteVRF(dcnmClient, fabricName, dn)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	setVRFAttributes(d, cont)
 
 	flag, err := checkvrfDeploy(dcnmClient, fabricName, dn)
 	if err != nil {
 		d.Set("deploy", false)
-		return err
+		return diag.FromErr(err)
 	}
 	d.Set("deploy", flag)
 
@@ -233,7 +234,7 @@ func datasourceDCNMVRFRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func getAttachmentList(client *client.Client, fabric, name string) ([]interface{}, error) {
+func getAttachmentList(client *Client, fabric, name string) ([]interface{}, error) {
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/attachments?vrf-names=%s", fabric, name)
 	cont, err := client.GetviaURL(durl)
 	if err != nil {
@@ -0,0 +1,123 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func datasourceDCNMVRFs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: datasourceDCNMVRFsRead,
+
+		Schema: map[string]*schema.Schema{
+			"fabric_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"name_regex": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+
+			"vrfs": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"segment_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"template": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"vlan_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"vlan_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getRemoteVRFs(client *Client, fabricName string) (*container.Container, error) {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs", fabricName)
+	return client.GetviaURL(durl)
+}
+
+func datasourceDCNMVRFsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ")
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+	nameRegex := nameFilter(d)
+
+	cont, err := getRemoteVRFs(dcnmClient, fabricName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	vrfs := make([]interface{}, 0, 1)
+	for i := 0; i < len(cont.Data().([]interface{})); i++ {
+		vrfCont := cont.Index(i)
+
+		name := stripQuotes(vrfCont.S("vrfName").String())
+		if !nameRegex.MatchString(name) {
+			continue
+		}
+
+		vrfMap := map[string]interface{}{
+			"name":       name,
+			"segment_id": stripQuotes(vrfCont.S("vrfId").String()),
+			"template":   stripQuotes(vrfCont.S("vrfTemplate").String()),
+		}
+		if config, err := cleanJsonString(stripQuotes(vrfCont.S("vrfTemplateConfig").String())); err == nil {
+			if vlan, err := strconv.Atoi(stripQuotes(config.S("vrfVlanId").String())); err == nil {
+				vrfMap["vlan_id"] = vlan
+			}
+			if config.Exists("vrfVlanName") {
+				vrfMap["vlan_name"] = stripQuotes(config.S("vrfVlanName").String())
+			}
+			if config.Exists("vrfDescription") {
+				vrfMap["description"] = stripQuotes(config.S("vrfDescription").String())
+			}
+		}
+		vrfs = append(vrfs, vrfMap)
+	}
+	d.Set("vrfs", vrfs)
+
+	d.SetId(fabricName)
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
@@ -0,0 +1,1280 @@
+package dcnm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const mockDCNMToken = "mock-dcnm-token"
+
+// mockDCNM is an in-process stand-in for the DCNM REST API. It keeps just
+// enough state for the provider resources to be driven through their whole
+// lifecycle without a real controller.
+type mockDCNM struct {
+	mu sync.Mutex
+
+	// ndfc serves the NDFC 12 API instead of the DCNM 11 one.
+	ndfc bool
+
+	// tokens are the tokens issued by logons, which are valid along with
+	// mockDCNMToken.
+	tokens map[string]bool
+
+	nextID int
+
+	fabrics      map[string]map[string]interface{}
+	switches     map[string][]map[string]interface{}
+	roles        map[string]string
+	vrfs         map[string]map[string]interface{}
+	vrfAttach    map[string]map[string]map[string]interface{}
+	networks     map[string]map[string]interface{}
+	netAttach    map[string]map[string]map[string]interface{}
+	interfaces   map[string]map[string]interface{}
+	intfDeployed map[string]bool
+	credentials  map[string]string
+	tasks        map[string]*mockTask
+
+	// failures are answered, in order, to the next requests instead of
+	// routing them, to simulate a controller which is temporarily failing.
+	failures []mockFailure
+
+	// calls counts the requests served, by method and path.
+	calls map[string]int
+
+	// headers keeps the headers of the last request, by method and path.
+	headers map[string]http.Header
+
+	routes []mockRoute
+}
+
+type mockFailure struct {
+	// path limits the failure to the requests for it, when set.
+	path    string
+	status  int
+	message string
+}
+
+// mockTask is an asynchronous operation, which is reported in progress for a
+// number of status checks before it completes with its result.
+type mockTask struct {
+	polls  int
+	result string
+}
+
+type mockRoute struct {
+	method  string
+	pattern *regexp.Regexp
+	handler func(w http.ResponseWriter, r *http.Request, params []string)
+}
+
+// newMockNDFC returns a controller serving the same objects as newMockDCNM,
+// behind the NDFC paths and Nexus Dashboard login.
+func newMockNDFC() *mockDCNM {
+	m := newMockDCNM()
+	m.ndfc = true
+	return m
+}
+
+func newMockDCNM() *mockDCNM {
+	m := &mockDCNM{
+		nextID:       1,
+		fabrics:      make(map[string]map[string]interface{}),
+		switches:     make(map[string][]map[string]interface{}),
+		roles:        make(map[string]string),
+		vrfs:         make(map[string]map[string]interface{}),
+		vrfAttach:    make(map[string]map[string]map[string]interface{}),
+		networks:     make(map[string]map[string]interface{}),
+		netAttach:    make(map[string]map[string]map[string]interface{}),
+		interfaces:   make(map[string]map[string]interface{}),
+		intfDeployed: make(map[string]bool),
+		tokens:       make(map[string]bool),
+		credentials:  make(map[string]string),
+		tasks:        make(map[string]*mockTask),
+		calls:        make(map[string]int),
+		headers:      make(map[string]http.Header),
+	}
+
+	m.route("POST", `/rest/logon`, m.logon)
+
+	m.route("GET", `/rest/control/fabrics/([^/]+)/inventory`, m.getInventory)
+	m.route("POST", `/rest/control/fabrics/([^/]+)/inventory/test-reachability`, m.testReachability)
+	m.route("POST", `/rest/control/fabrics/([^/]+)/inventory/discover`, m.discoverSwitch)
+	m.route("DELETE", `/rest/control/fabrics/([^/]+)/switches/([^/]+)`, m.deleteSwitch)
+	m.route("GET", `/rest/control/fabrics/([^/]+)/config-preview(?:/([^/]+))?`, m.configPreview)
+	m.route("POST", `/rest/control/fabrics/([^/]+)/config-deploy(?:/([^/]+))?`, m.configDeploy)
+	m.route("POST", `/rest/control/fabrics/([^/]+)/config-save`, m.ok)
+	m.route("GET", `/rest/control/switches/roles`, m.getRoles)
+	m.route("POST", `/rest/control/switches/roles`, m.setRoles)
+	m.route("POST", `/fm/fmrest/lanConfig/saveSwitchCredentials`, m.saveCredentials)
+
+	m.route("POST", `/rest/imagemanagement/rest/imageupgrade/upgrade-image`, m.startTask)
+	m.route("GET", `/rest/imagemanagement/rest/imageupgrade/upgrade-status/([^/]+)`, m.getTask)
+
+	m.route("GET", `/rest/control/fabrics/([^/]+)`, m.getFabric)
+	m.route("POST", `/rest/control/fabrics/([^/]+)/([^/]+)`, m.saveFabric)
+	m.route("PUT", `/rest/control/fabrics/([^/]+)/([^/]+)`, m.saveFabric)
+	m.route("DELETE", `/rest/control/fabrics/([^/]+)`, m.deleteFabric)
+
+	m.route("POST", `/rest/managed-pool/fabrics/([^/]+)/partitions/ids`, m.nextSegment("partitionSegmentId", 50000))
+	m.route("POST", `/rest/managed-pool/fabrics/([^/]+)/segments/ids`, m.nextSegment("segmentId", 30000))
+	m.route("GET", `/rest/resource-manager/vlan/([^/]+)`, m.nextVlan)
+
+	m.route("GET", `/rest/top-down/fabrics/([^/]+)/vrfs/attachments`, m.getVRFAttachments)
+	m.route("POST", `/rest/top-down/fabrics/([^/]+)/vrfs/attachments`, m.attachVRF)
+	m.route("POST", `/rest/top-down/fabrics/([^/]+)/vrfs/deployments`, m.deployVRF)
+	m.route("POST", `/rest/top-down/fabrics/([^/]+)/vrfs/deploy`, m.deployVRFSwitches)
+	m.route("GET", `/rest/top-down/fabrics/([^/]+)/vrfs`, m.listObjects(m.vrfs))
+	m.route("POST", `/rest/top-down/fabrics/([^/]+)/vrfs`, m.createObject(m.vrfs, "vrfName"))
+	m.route("GET", `/rest/top-down/fabrics/([^/]+)/vrfs/([^/]+)`, m.getObject(m.vrfs))
+	m.route("PUT", `/rest/top-down/fabrics/([^/]+)/vrfs/([^/]+)`, m.updateObject(m.vrfs))
+	m.route("DELETE", `/rest/top-down/fabrics/([^/]+)/vrfs/([^/]+)`, m.deleteObject(m.vrfs, m.vrfAttach))
+
+	m.route("POST", `/rest/top-down/fabrics/([^/]+)/networks/attachments`, m.attachNetwork)
+	m.route("POST", `/rest/top-down/fabrics/([^/]+)/networks/deployments`, m.deployNetworks)
+	m.route("POST", `/rest/top-down/fabrics/([^/]+)/networks/deploy`, m.deployNetworkSwitches)
+	m.route("GET", `/rest/top-down/fabrics/([^/]+)/networks/([^/]+)/attachments`, m.getNetworkAttachments)
+	m.route("POST", `/rest/top-down/fabrics/([^/]+)/networks/([^/]+)/deploy`, m.deployNetwork)
+	m.route("GET", `/rest/top-down/fabrics/([^/]+)/networks`, m.listObjects(m.networks))
+	m.route("POST", `/rest/top-down/fabrics/([^/]+)/networks`, m.createObject(m.networks, "networkName"))
+	m.route("GET", `/rest/top-down/fabrics/([^/]+)/networks/([^/]+)`, m.getObject(m.networks))
+	m.route("PUT", `/rest/top-down/fabrics/([^/]+)/networks/([^/]+)`, m.updateObject(m.networks))
+	m.route("DELETE", `/rest/top-down/fabrics/([^/]+)/networks/([^/]+)`, m.deleteObject(m.networks, m.netAttach))
+
+	m.route("GET", `/rest/interface/detail`, m.getInterfaceDetail)
+	m.route("POST", `/rest/interface/deploy`, m.deployInterface)
+	m.route("POST", `/rest/interface/breakout`, m.breakoutInterface)
+	m.route("DELETE", `/rest/interface/breakout`, m.unbreakoutInterface)
+	m.route("GET", `/rest/interface`, m.getInterface)
+	m.route("POST", `/rest/interface`, m.saveInterface)
+	m.route("PUT", `/rest/interface`, m.saveInterface)
+	m.route("DELETE", `/rest/interface`, m.deleteInterface)
+
+	m.seed()
+	return m
+}
+
+// seed populates the controller with a fabric holding two leaf switches, each
+// with a handful of ethernet ports, which is what most tests build upon.
+func (m *mockDCNM) seed() {
+	m.fabrics["fab1"] = map[string]interface{}{
+		"id":           m.newID(),
+		"fabricId":     "FABRIC-1",
+		"fabricName":   "fab1",
+		"templateName": "Easy_Fabric",
+		"nvPairs": map[string]interface{}{
+			"FABRIC_NAME":      "fab1",
+			"BGP_AS":           "65000",
+			"REPLICATION_MODE": "Multicast",
+		},
+	}
+
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		name := fmt.Sprintf("leaf%d", i+1)
+		serial := m.addSwitch("fab1", ip, name, "In-Sync")
+		m.roles[serial] = "leaf"
+
+		for port := 1; port <= 4; port++ {
+			ifName := fmt.Sprintf("Ethernet1/%d", port)
+			m.interfaces[mockIntfKey(serial, ifName)] = map[string]interface{}{
+				"policy":        "int_trunk_host_11_1",
+				"interfaceType": "INTERFACE_ETHERNET",
+				"serialNumber":  serial,
+				"ifName":        ifName,
+				"nvPairs": map[string]interface{}{
+					"INTF_NAME":   ifName,
+					"FABRIC_NAME": "fab1",
+					"ADMIN_STATE": "true",
+					"MTU":         "jumbo",
+					"SPEED":       "Auto",
+					"DESC":        "",
+				},
+			}
+			m.intfDeployed[mockIntfKey(serial, ifName)] = true
+		}
+	}
+}
+
+func (m *mockDCNM) route(method, pattern string, handler func(w http.ResponseWriter, r *http.Request, params []string)) {
+	m.routes = append(m.routes, mockRoute{
+		method:  method,
+		pattern: regexp.MustCompile("^" + pattern + "$"),
+		handler: handler,
+	})
+}
+
+func (m *mockDCNM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token := r.Header.Get("dcnm-token")
+	if m.ndfc {
+		if !m.serveNDFC(w, r) {
+			return
+		}
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+
+	// requests are authenticated by the token, or by a client certificate.
+	clientCert := r.TLS != nil && len(r.TLS.PeerCertificates) > 0
+	if r.URL.Path != "/rest/logon" && token != mockDCNMToken && !m.tokens[token] && !clientCert {
+		mockWrite(w, http.StatusUnauthorized, map[string]interface{}{"message": "Unauthorized"})
+		return
+	}
+
+	m.calls[r.Method+" "+r.URL.Path]++
+	m.headers[r.Method+" "+r.URL.Path] = r.Header.Clone()
+
+	for i, failure := range m.failures {
+		if r.URL.Path == "/rest/logon" || (failure.path != "" && failure.path != r.URL.Path) {
+			continue
+		}
+		m.failures = append(m.failures[:i], m.failures[i+1:]...)
+		mockWrite(w, failure.status, map[string]interface{}{"message": failure.message})
+		return
+	}
+
+	for _, rt := range m.routes {
+		if rt.method != r.Method {
+			continue
+		}
+		if params := rt.pattern.FindStringSubmatch(r.URL.Path); params != nil {
+			rt.handler(w, r, params[1:])
+			return
+		}
+	}
+	mockWrite(w, http.StatusNotFound, map[string]interface{}{"message": fmt.Sprintf("%s %s not found", r.Method, r.URL.Path)})
+}
+
+// serveNDFC answers the NDFC login and version requests, and maps the other
+// NDFC paths to the DCNM ones, which are then routed as usual. It returns
+// whether the request still has to be routed.
+func (m *mockDCNM) serveNDFC(w http.ResponseWriter, r *http.Request) bool {
+	const lanFabric = "/appcenter/cisco/ndfc/api/v1/lan-fabric/rest/"
+
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/login":
+		m.calls[r.Method+" "+r.URL.Path]++
+		body, _ := mockBody(r).(map[string]interface{})
+		if body["userName"] == "" || body["userPasswd"] == "" || body["domain"] == "" {
+			mockWrite(w, http.StatusUnauthorized, map[string]interface{}{"message": "Invalid credentials"})
+			return false
+		}
+		mockWrite(w, http.StatusOK, map[string]interface{}{"jwttoken": m.newToken()})
+		return false
+	case r.Method == "GET" && r.URL.Path == ndfcVersionPath:
+		mockWrite(w, http.StatusOK, map[string]interface{}{"version": "12.1.2e"})
+		return false
+	case strings.HasPrefix(r.URL.Path, lanFabric) && r.URL.Path != lanFabric+"logon":
+		r.URL.Path = "/rest/" + strings.TrimPrefix(r.URL.Path, lanFabric)
+		return true
+	}
+
+	mockNotFound(w, r.URL.Path)
+	return false
+}
+
+// failNext makes the next count requests fail with the given status and
+// message.
+func (m *mockDCNM) failNext(count, status int, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failures = nil
+	for i := 0; i < count; i++ {
+		m.failures = append(m.failures, mockFailure{status: status, message: message})
+	}
+}
+
+// failPath makes the next count requests for path fail with the given status
+// and message, the other requests are answered as usual.
+func (m *mockDCNM) failPath(path string, count, status int, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failures = nil
+	for i := 0; i < count; i++ {
+		m.failures = append(m.failures, mockFailure{path: path, status: status, message: message})
+	}
+}
+
+// callCount returns the number of requests served for the method and path.
+func (m *mockDCNM) callCount(method, path string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls[method+" "+path]
+}
+
+// lastHeaders returns the headers of the last request served for the method
+// and path.
+func (m *mockDCNM) lastHeaders(method, path string) http.Header {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.headers[method+" "+path]
+}
+
+// outOfBand runs f against the controller state, to simulate changes made
+// outside of Terraform.
+func (m *mockDCNM) outOfBand(f func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f()
+}
+
+func (m *mockDCNM) newToken() string {
+	token := fmt.Sprintf("%s-%d", mockDCNMToken, m.newID())
+	m.tokens[token] = true
+	return token
+}
+
+// revokeTokens invalidates the tokens issued so far, as a controller restart
+// would.
+func (m *mockDCNM) revokeTokens() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tokens = make(map[string]bool)
+}
+
+func (m *mockDCNM) newID() int {
+	m.nextID++
+	return m.nextID
+}
+
+func (m *mockDCNM) addSwitch(fabric, ip, name, status string) string {
+	id := m.newID()
+	serial := fmt.Sprintf("SAL%04d", id)
+	m.switches[fabric] = append(m.switches[fabric], map[string]interface{}{
+		"ipAddress":    ip,
+		"fabricName":   fabric,
+		"logicalName":  name,
+		"switchDbID":   fmt.Sprintf("%d", id),
+		"serialNumber": serial,
+		"model":        "N9K-C9300v",
+		"mode":         "Normal",
+		"status":       status,
+	})
+	return serial
+}
+
+func mockWrite(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if body != nil {
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+func mockNotFound(w http.ResponseWriter, what string) {
+	mockWrite(w, http.StatusNotFound, map[string]interface{}{"message": fmt.Sprintf("%s not found", what)})
+}
+
+func mockBody(r *http.Request) interface{} {
+	var body interface{}
+	data, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(data, &body)
+	return body
+}
+
+func mockIntfKey(serial, name string) string {
+	return fmt.Sprintf("%s~%s", serial, strings.ToLower(name))
+}
+
+func (m *mockDCNM) ok(w http.ResponseWriter, r *http.Request, params []string) {
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) logon(w http.ResponseWriter, r *http.Request, params []string) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Basic ") {
+		mockWrite(w, http.StatusInternalServerError, map[string]interface{}{"message": "Invalid credentials"})
+		return
+	}
+	mockWrite(w, http.StatusOK, map[string]interface{}{"Dcnm-Token": m.newToken()})
+}
+
+// saveCredentials takes a form encoded body, like DCNM does, and answers
+// without a JSON body.
+func (m *mockDCNM) saveCredentials(w http.ResponseWriter, r *http.Request, params []string) {
+	data, _ := ioutil.ReadAll(r.Body)
+	form, err := url.ParseQuery(string(data))
+	if err != nil || form.Get("switchIds") == "" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "switchIds is required")
+		return
+	}
+
+	for _, id := range strings.Split(form.Get("switchIds"), ",") {
+		m.credentials[id] = form.Get("userName")
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// startTask starts a task lasting for the "polls" status checks of the body,
+// which then ends with its "result".
+func (m *mockDCNM) startTask(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).(map[string]interface{})
+	polls, _ := body["polls"].(float64)
+	id := fmt.Sprintf("task-%d", m.newID())
+	m.tasks[id] = &mockTask{polls: int(polls), result: fmt.Sprint(body["result"])}
+	mockWrite(w, http.StatusOK, map[string]interface{}{"taskId": id})
+}
+
+func (m *mockDCNM) getTask(w http.ResponseWriter, r *http.Request, params []string) {
+	task, ok := m.tasks[params[0]]
+	if !ok {
+		mockNotFound(w, "task")
+		return
+	}
+
+	state := task.result
+	if task.polls > 0 {
+		task.polls--
+		state = "IN_PROGRESS"
+	}
+	mockWrite(w, http.StatusOK, map[string]interface{}{
+		"taskId": params[0],
+		"status": map[string]interface{}{"state": state},
+	})
+}
+
+func (m *mockDCNM) getFabric(w http.ResponseWriter, r *http.Request, params []string) {
+	fabric, ok := m.fabrics[params[0]]
+	if !ok {
+		mockNotFound(w, "fabric")
+		return
+	}
+	mockWrite(w, http.StatusOK, fabric)
+}
+
+func (m *mockDCNM) saveFabric(w http.ResponseWriter, r *http.Request, params []string) {
+	name, template := params[0], params[1]
+	nvPairs, _ := mockBody(r).(map[string]interface{})
+
+	fabric, exists := m.fabrics[name]
+	if r.Method == "POST" {
+		if exists {
+			mockWrite(w, http.StatusBadRequest, map[string]interface{}{"message": "fabric already exists"})
+			return
+		}
+		id := m.newID()
+		fabric = map[string]interface{}{
+			"id":         id,
+			"fabricId":   fmt.Sprintf("FABRIC-%d", id),
+			"fabricName": name,
+		}
+		m.fabrics[name] = fabric
+	} else if !exists {
+		mockNotFound(w, "fabric")
+		return
+	}
+	fabric["templateName"] = template
+	fabric["nvPairs"] = nvPairs
+	mockWrite(w, http.StatusOK, fabric)
+}
+
+func (m *mockDCNM) deleteFabric(w http.ResponseWriter, r *http.Request, params []string) {
+	if _, ok := m.fabrics[params[0]]; !ok {
+		mockNotFound(w, "fabric")
+		return
+	}
+	delete(m.fabrics, params[0])
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) getInventory(w http.ResponseWriter, r *http.Request, params []string) {
+	if _, ok := m.fabrics[params[0]]; !ok {
+		mockNotFound(w, "fabric")
+		return
+	}
+	list := make([]interface{}, 0, 1)
+	for _, sw := range m.switches[params[0]] {
+		list = append(list, sw)
+	}
+	mockWrite(w, http.StatusOK, list)
+}
+
+func (m *mockDCNM) testReachability(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).(map[string]interface{})
+	ip := fmt.Sprint(body["seedIP"])
+	mockWrite(w, http.StatusOK, []interface{}{
+		map[string]interface{}{
+			"reachable":    true,
+			"auth":         true,
+			"known":        false,
+			"valid":        true,
+			"selectable":   true,
+			"sysName":      fmt.Sprintf("switch-%s", ip),
+			"ipaddr":       ip,
+			"platform":     "N9K",
+			"version":      "9.3(5)",
+			"hopCount":     0,
+			"deviceIndex":  fmt.Sprintf("switch-%s", ip),
+			"statusReason": "manageable",
+		},
+	})
+}
+
+func (m *mockDCNM) discoverSwitch(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).(map[string]interface{})
+	switches, _ := body["switches"].([]interface{})
+	for _, val := range switches {
+		sw := val.(map[string]interface{})
+		m.addSwitch(params[0], fmt.Sprint(sw["ipaddr"]), fmt.Sprint(sw["sysName"]), "Out-of-Sync")
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) deleteSwitch(w http.ResponseWriter, r *http.Request, params []string) {
+	switches := m.switches[params[0]]
+	for i, sw := range switches {
+		if sw["serialNumber"] == params[1] {
+			m.switches[params[0]] = append(switches[:i], switches[i+1:]...)
+			mockWrite(w, http.StatusOK, nil)
+			return
+		}
+	}
+	mockNotFound(w, "switch")
+}
+
+func (m *mockDCNM) configPreview(w http.ResponseWriter, r *http.Request, params []string) {
+	list := make([]interface{}, 0, 1)
+	for _, sw := range m.switches[params[0]] {
+		if params[1] == "" || sw["serialNumber"] == params[1] {
+			list = append(list, map[string]interface{}{
+				"switchId": sw["serialNumber"],
+				"status":   sw["status"],
+			})
+		}
+	}
+	mockWrite(w, http.StatusOK, list)
+}
+
+func (m *mockDCNM) configDeploy(w http.ResponseWriter, r *http.Request, params []string) {
+	for _, sw := range m.switches[params[0]] {
+		sw["status"] = "In-Sync"
+	}
+
+	// deploying switches also deploys their interfaces.
+	for _, serial := range strings.Split(params[1], ",") {
+		for key := range m.intfDeployed {
+			if serial != "" && strings.HasPrefix(key, serial+"~") {
+				m.intfDeployed[key] = true
+			}
+		}
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) getRoles(w http.ResponseWriter, r *http.Request, params []string) {
+	serial := r.URL.Query().Get("serialNumber")
+	mockWrite(w, http.StatusOK, []interface{}{
+		map[string]interface{}{
+			"serialNumber": serial,
+			"role":         m.roles[serial],
+		},
+	})
+}
+
+func (m *mockDCNM) setRoles(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).([]interface{})
+	for _, val := range body {
+		role := val.(map[string]interface{})
+		m.roles[fmt.Sprint(role["serialNumber"])] = fmt.Sprint(role["role"])
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) nextSegment(key string, base int) func(w http.ResponseWriter, r *http.Request, params []string) {
+	return func(w http.ResponseWriter, r *http.Request, params []string) {
+		mockWrite(w, http.StatusOK, map[string]interface{}{key: base + m.newID()})
+	}
+}
+
+func (m *mockDCNM) nextVlan(w http.ResponseWriter, r *http.Request, params []string) {
+	mockWrite(w, http.StatusOK, 2000+m.newID())
+}
+
+func (m *mockDCNM) createObject(store map[string]map[string]interface{}, nameKey string) func(w http.ResponseWriter, r *http.Request, params []string) {
+	return func(w http.ResponseWriter, r *http.Request, params []string) {
+		body, _ := mockBody(r).(map[string]interface{})
+		key := fmt.Sprintf("%s/%s", params[0], body[nameKey])
+		if _, ok := store[key]; ok {
+			mockWrite(w, http.StatusBadRequest, map[string]interface{}{"message": "object already exists"})
+			return
+		}
+		store[key] = body
+		mockWrite(w, http.StatusOK, body)
+	}
+}
+
+// listObjects answers the objects of a fabric, ordered by name.
+func (m *mockDCNM) listObjects(store map[string]map[string]interface{}) func(w http.ResponseWriter, r *http.Request, params []string) {
+	return func(w http.ResponseWriter, r *http.Request, params []string) {
+		if _, ok := m.fabrics[params[0]]; !ok {
+			mockNotFound(w, "fabric")
+			return
+		}
+
+		keys := make([]string, 0, 1)
+		for key := range store {
+			if strings.HasPrefix(key, params[0]+"/") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		list := make([]interface{}, 0, 1)
+		for _, key := range keys {
+			list = append(list, store[key])
+		}
+		mockWrite(w, http.StatusOK, list)
+	}
+}
+
+func (m *mockDCNM) getObject(store map[string]map[string]interface{}) func(w http.ResponseWriter, r *http.Request, params []string) {
+	return func(w http.ResponseWriter, r *http.Request, params []string) {
+		obj, ok := store[strings.Join(params, "/")]
+		if !ok {
+			mockNotFound(w, params[1])
+			return
+		}
+		mockWrite(w, http.StatusOK, obj)
+	}
+}
+
+func (m *mockDCNM) updateObject(store map[string]map[string]interface{}) func(w http.ResponseWriter, r *http.Request, params []string) {
+	return func(w http.ResponseWriter, r *http.Request, params []string) {
+		key := strings.Join(params, "/")
+		if _, ok := store[key]; !ok {
+			mockNotFound(w, params[1])
+			return
+		}
+		body, _ := mockBody(r).(map[string]interface{})
+		store[key] = body
+		mockWrite(w, http.StatusOK, body)
+	}
+}
+
+func (m *mockDCNM) deleteObject(store map[string]map[string]interface{}, attach map[string]map[string]map[string]interface{}) func(w http.ResponseWriter, r *http.Request, params []string) {
+	return func(w http.ResponseWriter, r *http.Request, params []string) {
+		key := strings.Join(params, "/")
+		if _, ok := store[key]; !ok {
+			mockNotFound(w, params[1])
+			return
+		}
+		delete(store, key)
+		delete(attach, key)
+		mockWrite(w, http.StatusOK, nil)
+	}
+}
+
+// attach records lanAttachList entries of a VRF or network attachment
+// request and answers with the per-switch status map DCNM returns.
+func (m *mockDCNM) attach(w http.ResponseWriter, r *http.Request, fabric, nameKey string, attach map[string]map[string]map[string]interface{}) {
+	result := make(map[string]interface{})
+
+	body, _ := mockBody(r).([]interface{})
+	for _, val := range body {
+		entry := val.(map[string]interface{})
+		key := fmt.Sprintf("%s/%s", fabric, entry[nameKey])
+		if attach[key] == nil {
+			attach[key] = make(map[string]map[string]interface{})
+		}
+
+		attachList, _ := entry["lanAttachList"].([]interface{})
+		for _, item := range attachList {
+			lan := item.(map[string]interface{})
+			serial := fmt.Sprint(lan["serialNumber"])
+			if !m.hasSwitch(fabric, serial) {
+				result[fmt.Sprintf("%s-[%s]", entry[nameKey], serial)] = "Invalid switch serial number"
+				continue
+			}
+
+			state, ok := attach[key][serial]
+			if !ok {
+				state = map[string]interface{}{
+					"switchSerialNo": serial,
+					"portNames":      nil,
+				}
+				attach[key][serial] = state
+			}
+			state["isLanAttached"] = lan["deployment"] == true
+			state["lanAttachState"] = "PENDING"
+			state["vlanId"] = lan["vlan"]
+			for _, field := range []string{"instanceValues", "freeformConfig", "extensionValues", "dot1QVlan", "untagged"} {
+				if val, ok := lan[field]; ok {
+					state[field] = val
+				}
+			}
+
+			ports := make([]string, 0, 1)
+			if state["portNames"] != nil {
+				ports = stringToList(state["portNames"].(string))
+			}
+			if sPorts, ok := lan["switchPorts"].(string); ok && sPorts != "" {
+				ports = append(ports, stringToList(sPorts)...)
+			}
+			if dsPorts, ok := lan["detachSwitchPorts"].(string); ok && dsPorts != "" {
+				ports = interfaceToStrList(difference(ports, stringToList(dsPorts)))
+			}
+			if lan["deployment"] != true {
+				ports = nil
+			}
+			if len(ports) > 0 {
+				state["portNames"] = strings.Join(ports, ",")
+			} else {
+				state["portNames"] = nil
+			}
+
+			result[fmt.Sprintf("%s-[%s]", entry[nameKey], serial)] = "SUCCESS"
+		}
+	}
+	mockWrite(w, http.StatusOK, result)
+}
+
+func (m *mockDCNM) hasSwitch(fabric, serial string) bool {
+	for _, sw := range m.switches[fabric] {
+		if sw["serialNumber"] == serial {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockDCNM) deploy(key string, attach map[string]map[string]map[string]interface{}) {
+	for _, state := range attach[key] {
+		m.deployState(state)
+	}
+}
+
+func (m *mockDCNM) deployState(state map[string]interface{}) {
+	if state["isLanAttached"] == true {
+		state["lanAttachState"] = "DEPLOYED"
+	} else {
+		state["lanAttachState"] = "NA"
+	}
+}
+
+// deploySwitches deploys the attachments listed per switch serial number,
+// the other switches are left pending.
+func (m *mockDCNM) deploySwitches(w http.ResponseWriter, r *http.Request, fabric string, attach map[string]map[string]map[string]interface{}) {
+	body, _ := mockBody(r).(map[string]interface{})
+	for serial, names := range body {
+		for _, name := range strings.Split(fmt.Sprint(names), ",") {
+			if state, ok := attach[fmt.Sprintf("%s/%s", fabric, name)][serial]; ok {
+				m.deployState(state)
+			}
+		}
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) attachList(key string, attach map[string]map[string]map[string]interface{}) []interface{} {
+	list := make([]interface{}, 0, 1)
+	for _, state := range attach[key] {
+		list = append(list, state)
+	}
+	return list
+}
+
+func (m *mockDCNM) getVRFAttachments(w http.ResponseWriter, r *http.Request, params []string) {
+	list := make([]interface{}, 0, 1)
+	for _, name := range strings.Split(r.URL.Query().Get("vrf-names"), ",") {
+		key := fmt.Sprintf("%s/%s", params[0], name)
+		if _, ok := m.vrfs[key]; !ok {
+			continue
+		}
+		list = append(list, map[string]interface{}{
+			"vrfName":       name,
+			"lanAttachList": m.attachList(key, m.vrfAttach),
+		})
+	}
+	mockWrite(w, http.StatusOK, list)
+}
+
+func (m *mockDCNM) attachVRF(w http.ResponseWriter, r *http.Request, params []string) {
+	m.attach(w, r, params[0], "vrfName", m.vrfAttach)
+}
+
+func (m *mockDCNM) deployVRF(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).(map[string]interface{})
+	for _, name := range strings.Split(fmt.Sprint(body["vrfNames"]), ",") {
+		m.deploy(fmt.Sprintf("%s/%s", params[0], name), m.vrfAttach)
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) deployVRFSwitches(w http.ResponseWriter, r *http.Request, params []string) {
+	m.deploySwitches(w, r, params[0], m.vrfAttach)
+}
+
+func (m *mockDCNM) getNetworkAttachments(w http.ResponseWriter, r *http.Request, params []string) {
+	key := strings.Join(params, "/")
+	if _, ok := m.networks[key]; !ok {
+		mockNotFound(w, params[1])
+		return
+	}
+	mockWrite(w, http.StatusOK, m.attachList(key, m.netAttach))
+}
+
+func (m *mockDCNM) attachNetwork(w http.ResponseWriter, r *http.Request, params []string) {
+	m.attach(w, r, params[0], "networkName", m.netAttach)
+}
+
+func (m *mockDCNM) deployNetwork(w http.ResponseWriter, r *http.Request, params []string) {
+	m.deploy(strings.Join(params, "/"), m.netAttach)
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) deployNetworks(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).(map[string]interface{})
+	for _, name := range strings.Split(fmt.Sprint(body["networkNames"]), ",") {
+		m.deploy(fmt.Sprintf("%s/%s", params[0], name), m.netAttach)
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) deployNetworkSwitches(w http.ResponseWriter, r *http.Request, params []string) {
+	m.deploySwitches(w, r, params[0], m.netAttach)
+}
+
+func (m *mockDCNM) findInterface(serial, name string) (string, map[string]interface{}) {
+	for key, intf := range m.interfaces {
+		serials := strings.Split(fmt.Sprint(intf["serialNumber"]), "~")
+		if (serials[0] == serial || intf["serialNumber"] == serial) && strings.EqualFold(fmt.Sprint(intf["ifName"]), name) {
+			return key, intf
+		}
+	}
+	return "", nil
+}
+
+func (m *mockDCNM) getInterface(w http.ResponseWriter, r *http.Request, params []string) {
+	query := r.URL.Query()
+	list := make([]interface{}, 0, 1)
+
+	// without a name, all of the interfaces of the switch are returned in
+	// one group per policy.
+	if query.Get("ifName") == "" {
+		keys := make([]string, 0, 1)
+		for key := range m.interfaces {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		groups := make(map[string]map[string]interface{})
+		for _, key := range keys {
+			intf := m.interfaces[key]
+			if strings.Split(fmt.Sprint(intf["serialNumber"]), "~")[0] != query.Get("serialNumber") {
+				continue
+			}
+			policy := fmt.Sprint(intf["policy"])
+			group, ok := groups[policy]
+			if !ok {
+				group = map[string]interface{}{
+					"policy":     intf["policy"],
+					"interfaces": []interface{}{},
+				}
+				groups[policy] = group
+				list = append(list, group)
+			}
+			group["interfaces"] = append(group["interfaces"].([]interface{}), intf)
+		}
+		mockWrite(w, http.StatusOK, list)
+		return
+	}
+
+	if _, intf := m.findInterface(query.Get("serialNumber"), query.Get("ifName")); intf != nil {
+		list = append(list, map[string]interface{}{
+			"policy":     intf["policy"],
+			"interfaces": []interface{}{intf},
+		})
+	}
+	mockWrite(w, http.StatusOK, list)
+}
+
+func (m *mockDCNM) saveInterface(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).(map[string]interface{})
+	interfaces, _ := body["interfaces"].([]interface{})
+	for _, val := range interfaces {
+		intf := val.(map[string]interface{})
+		key := mockIntfKey(fmt.Sprint(intf["serialNumber"]), fmt.Sprint(intf["ifName"]))
+
+		existing, ok := m.interfaces[key]
+		if r.Method == "PUT" && !ok {
+			mockWrite(w, http.StatusOK, []interface{}{
+				map[string]interface{}{"reportItemType": "ERROR", "message": "interface not found"},
+			})
+			return
+		}
+
+		// DCNM hands every template parameter back as a string
+		nvPairs := make(map[string]interface{})
+		if raw, ok := intf["nvPairs"].(map[string]interface{}); ok {
+			for k, v := range raw {
+				nvPairs[k] = fmt.Sprint(v)
+			}
+		}
+		if _, set := nvPairs["FABRIC_NAME"]; !set && intf["fabricName"] != nil {
+			nvPairs["FABRIC_NAME"] = intf["fabricName"]
+		}
+		if ok && existing["nvPairs"] != nil {
+			for k, v := range existing["nvPairs"].(map[string]interface{}) {
+				if _, set := nvPairs[k]; !set {
+					nvPairs[k] = v
+				}
+			}
+		}
+
+		// updates may leave the interface type out, DCNM keeps the known one
+		intfType := intf["interfaceType"]
+		if intfType == nil && ok {
+			intfType = existing["interfaceType"]
+		}
+
+		m.interfaces[key] = map[string]interface{}{
+			"policy":        body["policy"],
+			"interfaceType": intfType,
+			"serialNumber":  intf["serialNumber"],
+			"ifName":        intf["ifName"],
+			"nvPairs":       nvPairs,
+		}
+		m.intfDeployed[key] = false
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) deleteInterface(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).([]interface{})
+	for _, val := range body {
+		intf := val.(map[string]interface{})
+		key := mockIntfKey(fmt.Sprint(intf["serialNumber"]), fmt.Sprint(intf["ifName"]))
+		delete(m.interfaces, key)
+		delete(m.intfDeployed, key)
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) deployInterface(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).([]interface{})
+	for _, val := range body {
+		intf := val.(map[string]interface{})
+		key := mockIntfKey(fmt.Sprint(intf["serialNumber"]), fmt.Sprint(intf["ifName"]))
+		if _, ok := m.interfaces[key]; ok {
+			m.intfDeployed[key] = true
+		}
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+// breakoutInterface replaces each port with the ports of its breakout map,
+// such as 4 ports for 25g-4x.
+func (m *mockDCNM) breakoutInterface(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).([]interface{})
+	for _, val := range body {
+		entry := val.(map[string]interface{})
+		serial := fmt.Sprint(entry["serialNumber"])
+		name := fmt.Sprint(entry["ifName"])
+
+		parentKey := mockIntfKey(serial, name)
+		parent, ok := m.interfaces[parentKey]
+		var speed, count int
+		if _, err := fmt.Sscanf(fmt.Sprint(entry["map"]), "%dg-%dx", &speed, &count); err != nil || !ok {
+			mockWrite(w, http.StatusBadRequest, map[string]interface{}{"message": fmt.Sprintf("invalid breakout of %s", name)})
+			return
+		}
+		delete(m.interfaces, parentKey)
+		delete(m.intfDeployed, parentKey)
+
+		for port := 1; port <= count; port++ {
+			ifName := fmt.Sprintf("%s/%d", name, port)
+			key := mockIntfKey(serial, ifName)
+			m.interfaces[key] = map[string]interface{}{
+				"policy":        parent["policy"],
+				"interfaceType": "INTERFACE_ETHERNET",
+				"serialNumber":  serial,
+				"ifName":        ifName,
+				"nvPairs": map[string]interface{}{
+					"INTF_NAME":   ifName,
+					"FABRIC_NAME": parent["nvPairs"].(map[string]interface{})["FABRIC_NAME"],
+					"ADMIN_STATE": "true",
+					"SPEED":       fmt.Sprintf("%dGb", speed),
+					"DESC":        "",
+				},
+			}
+			m.intfDeployed[key] = false
+		}
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+// unbreakoutInterface joins the ports of a breakout back into a single port.
+func (m *mockDCNM) unbreakoutInterface(w http.ResponseWriter, r *http.Request, params []string) {
+	body, _ := mockBody(r).([]interface{})
+	for _, val := range body {
+		entry := val.(map[string]interface{})
+		serial := fmt.Sprint(entry["serialNumber"])
+		name := fmt.Sprint(entry["ifName"])
+
+		fabric := ""
+		for key, intf := range m.interfaces {
+			if strings.HasPrefix(key, mockIntfKey(serial, name+"/")) {
+				fabric = fmt.Sprint(intf["nvPairs"].(map[string]interface{})["FABRIC_NAME"])
+				delete(m.interfaces, key)
+				delete(m.intfDeployed, key)
+			}
+		}
+		if fabric == "" {
+			mockNotFound(w, fmt.Sprintf("breakout of %s", name))
+			return
+		}
+
+		key := mockIntfKey(serial, name)
+		m.interfaces[key] = map[string]interface{}{
+			"policy":        "int_trunk_host_11_1",
+			"interfaceType": "INTERFACE_ETHERNET",
+			"serialNumber":  serial,
+			"ifName":        name,
+			"nvPairs": map[string]interface{}{
+				"INTF_NAME":   name,
+				"FABRIC_NAME": fabric,
+				"ADMIN_STATE": "true",
+				"SPEED":       "Auto",
+				"DESC":        "",
+			},
+		}
+		m.intfDeployed[key] = false
+	}
+	mockWrite(w, http.StatusOK, nil)
+}
+
+func (m *mockDCNM) getInterfaceDetail(w http.ResponseWriter, r *http.Request, params []string) {
+	serial := r.URL.Query().Get("serialNumber")
+
+	keys := make([]string, 0, 1)
+	for key := range m.interfaces {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := make([]interface{}, 0, 1)
+	for _, key := range keys {
+		intf := m.interfaces[key]
+		serials := strings.Split(fmt.Sprint(intf["serialNumber"]), "~")
+		if serials[0] != serial {
+			continue
+		}
+		status := "Pending"
+		if m.intfDeployed[key] {
+			status = "In-Sync"
+		}
+		nvPairs, _ := intf["nvPairs"].(map[string]interface{})
+		adminStatus := "up"
+		if nvPairs["ADMIN_STATE"] == "false" {
+			adminStatus = "down"
+		}
+		list = append(list, map[string]interface{}{
+			"entityId":         fmt.Sprintf("%s~%s", intf["serialNumber"], intf["ifName"]),
+			"ifName":           intf["ifName"],
+			"ifType":           intf["interfaceType"],
+			"serialNo":         intf["serialNumber"],
+			"fabricName":       nvPairs["FABRIC_NAME"],
+			"alias":            nvPairs["DESC"],
+			"adminStatusStr":   adminStatus,
+			"operStatusStr":    adminStatus,
+			"complianceStatus": status,
+		})
+	}
+	mockWrite(w, http.StatusOK, list)
+}
+
+var testMockServer *mockDCNM
+
+var testMockURL string
+
+var testMockOnce sync.Once
+
+// testMockStart starts the mock controller shared by the tests.
+func testMockStart() {
+	testMockOnce.Do(func() {
+		testMockServer = newMockDCNM()
+		testMockURL = httptest.NewServer(testMockServer).URL
+	})
+}
+
+// testMockConfigClient returns a client configured by config, with retries
+// short enough for the tests.
+func testMockConfigClient(t *testing.T, config Config) *Client {
+	t.Helper()
+
+	dcnmClient, err := config.getClient()
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	dcnmClient.retry = retryPolicy{
+		MaxRetries:  3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+		StatusCodes: map[int]bool{http.StatusServiceUnavailable: true},
+	}
+	return dcnmClient
+}
+
+// testMockClient returns a client logged in to the shared mock controller
+// with a username and password.
+func testMockClient(t *testing.T) *Client {
+	testMockStart()
+	return testMockConfigClient(t, Config{
+		URL:      testMockURL,
+		Username: "admin",
+		Password: "admin",
+		Expiry:   900000,
+	})
+}
+
+// testMockApply plans raw configuration against state and applies the result,
+// in the same way Terraform core drives a resource.
+func testMockApply(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, meta interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if diff == nil || diff.Empty() {
+		return state
+	}
+
+	newState, diags := r.Apply(context.Background(), state, diff, meta)
+	if diags.HasError() {
+		t.Fatalf("err : %v", diags)
+	}
+	return newState
+}
+
+// testMockPlanEmpty fails the test when refreshing state and planning raw
+// configuration against it would still produce changes.
+func testMockPlanEmpty(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, meta interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	state = testMockRefresh(t, r, state, meta)
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Fatalf("expected empty plan, got : %#v", diff.Attributes)
+	}
+	return state
+}
+
+// testMockPlanChanged fails the test unless refreshing state and planning raw
+// configuration against it produces changes.
+func testMockPlanChanged(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, meta interface{}) {
+	t.Helper()
+
+	state = testMockRefresh(t, r, state, meta)
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if diff == nil || diff.Empty() {
+		t.Fatalf("expected changes to be planned")
+	}
+}
+
+func testMockRefresh(t *testing.T, r *schema.Resource, state *terraform.InstanceState, meta interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	newState, diags := r.RefreshWithoutUpgrade(context.Background(), state, meta)
+	if diags.HasError() {
+		t.Fatalf("err : %v", diags)
+	}
+	return newState
+}
+
+// testMockRefreshGone fails the test unless refreshing state finds the object
+// deleted and drops it.
+func testMockRefreshGone(t *testing.T, r *schema.Resource, state *terraform.InstanceState, meta interface{}) {
+	t.Helper()
+
+	if newState := testMockRefresh(t, r, state, meta); newState != nil && newState.ID != "" {
+		t.Fatalf("expected object to be removed from state, got id %q", newState.ID)
+	}
+}
+
+func testMockDestroy(t *testing.T, r *schema.Resource, state *terraform.InstanceState, meta interface{}) {
+	t.Helper()
+
+	_, diags := r.Apply(context.Background(), state, &terraform.InstanceDiff{Destroy: true}, meta)
+	if diags.HasError() {
+		t.Fatalf("err : %v", diags)
+	}
+}
+
+func testMockImport(t *testing.T, r *schema.Resource, id string, meta interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	d := r.Data(&terraform.InstanceState{ID: id})
+	imported, err := r.Importer.StateContext(context.Background(), d, meta)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if len(imported) != 1 {
+		t.Fatalf("expected one imported object, got %d", len(imported))
+	}
+	return imported[0].State()
+}
+
+func testMockReadData(t *testing.T, r *schema.Resource, raw map[string]interface{}, meta interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+
+	state, diags := r.ReadDataApply(context.Background(), diff, meta)
+	if diags.HasError() {
+		t.Fatalf("err : %v", diags)
+	}
+	return state
+}
+
+func testMockCheckAttr(t *testing.T, state *terraform.InstanceState, key, value string) {
+	t.Helper()
+
+	if state == nil {
+		t.Fatalf("expected state with %s = %q, got no state", key, value)
+	}
+	if got := state.Attributes[key]; got != value {
+		t.Fatalf("expected %s = %q, got %q", key, value, got)
+	}
+}
+
+// testMockSerial returns the serial number of a switch in the mock fabric.
+func testMockSerial(t *testing.T, dcnmClient *Client, name string) string {
+	t.Helper()
+
+	switchCont, err := getRemoteSwitchforDS(dcnmClient, "fab1", name)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	return stripQuotes(switchCont.S("serialNumber").String())
+}
@@ -0,0 +1,113 @@
+package dcnm
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	platformDCNM = "dcnm"
+	platformNDFC = "ndfc"
+	platformAuto = "auto"
+)
+
+var platforms = []string{
+	platformDCNM,
+	platformNDFC,
+	platformAuto,
+}
+
+// ndfcVersionPath only exists on NDFC, which is how the platform is detected.
+const ndfcVersionPath = "/appcenter/cisco/ndfc/api/about/version"
+
+// ndfcPathPrefixes maps the DCNM 11 paths used by the resources to the NDFC 12
+// ones. The first matching prefix is replaced.
+var ndfcPathPrefixes = []struct {
+	dcnm string
+	ndfc string
+}{
+	{"/fm/fmrest/", "/appcenter/cisco/ndfc/api/v1/lan-fabric/rest/"},
+	{"/rest/", "/appcenter/cisco/ndfc/api/v1/lan-fabric/rest/"},
+}
+
+// mapPath returns the path of the DCNM 11 endpoint on the platform of the
+// controller. Paths which are already NDFC ones are left as they are, and
+// paths without a leading slash are taken from the root.
+func (c *Client) mapPath(path string) string {
+	if c.platform != platformNDFC {
+		return path
+	}
+
+	path = "/" + strings.TrimPrefix(path, "/")
+	for _, prefix := range ndfcPathPrefixes {
+		if strings.HasPrefix(path, prefix.dcnm) {
+			return prefix.ndfc + strings.TrimPrefix(path, prefix.dcnm)
+		}
+	}
+	return path
+}
+
+// detectPlatform sets the platform of a client configured with "auto", by
+// asking the controller for its NDFC version. DCNM 11 answers with an error
+// status, which is not an error here. Every request goes through it, so the
+// platform is only read once it is set.
+func (c *Client) detectPlatform() error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.platform != platformAuto {
+		return nil
+	}
+
+	req, err := c.newRequest("GET", ndfcVersionPath, nil)
+	if err != nil {
+		return err
+	}
+	cont, resp, err := c.sendRequest(req)
+	if resp == nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusOK && cont != nil {
+		log.Printf("[DEBUG] Detected NDFC version %s", stripQuotes(cont.S("version").String()))
+		c.platform = platformNDFC
+	} else {
+		log.Printf("[DEBUG] Detected DCNM, NDFC version returned status %d", resp.StatusCode)
+		c.platform = platformDCNM
+	}
+	return nil
+}
+
+// authenticateNDFC logs in to Nexus Dashboard, for a token used as a bearer
+// token.
+func (c *Client) authenticateNDFC() (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"userName":   c.username,
+		"userPasswd": c.password,
+		"domain":     c.domain,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := c.newRequest("POST", "/login", body)
+	if err != nil {
+		return "", err
+	}
+
+	cont, resp, err := c.sendRequest(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return "", fmt.Errorf("Invalid username or password")
+	}
+	if err := checkforerrors(cont, resp); err != nil {
+		return "", err
+	}
+
+	return stripQuotes(cont.S("jwttoken").String()), nil
+}
@@ -1,10 +1,19 @@
 package dcnm
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func Provider() *schema.Provider {
@@ -12,18 +21,51 @@ func Provider() *schema.Provider {
 		Schema: map[string]*schema.Schema{
 			"username": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("DCNM_USERNAME", nil),
 				Description: "Username for the DCNM account",
 			},
 
 			"password": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("DCNM_PASSWORD", nil),
 				Description: "Password for the DCNM account",
 			},
 
+			"token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("DCNM_TOKEN", nil),
+				Description: "Pre-issued API token, used instead of the username and password",
+			},
+
+			"ca_cert": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"DCNM_CA_CERT", "DCNM_CA_FILE"}, nil),
+				Description: "PEM encoded CA bundle, or the path to it, used to verify the DCNM server certificate",
+			},
+
+			"client_cert": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("DCNM_CLIENT_CERT", nil),
+				RequiredWith: []string{"client_key"},
+				Description:  "PEM encoded client certificate, or the path to it, used to authenticate with DCNM",
+			},
+
+			"client_key": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				DefaultFunc:  schema.EnvDefaultFunc("DCNM_CLIENT_KEY", nil),
+				RequiredWith: []string{"client_cert"},
+				Description:  "PEM encoded private key of client_cert, or the path to it",
+			},
+
 			"url": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
@@ -31,11 +73,26 @@ func Provider() *schema.Provider {
 				Description: "URL for the DCNM server",
 			},
 
+			"platform": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("DCNM_PLATFORM", platformDCNM),
+				ValidateFunc: validation.StringInSlice(platforms, false),
+				Description:  "API of the controller, \"dcnm\" for DCNM 11, \"ndfc\" for NDFC 12 or \"auto\" to detect it",
+			},
+
+			"login_domain": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DCNM_LOGIN_DOMAIN", "DefaultAuth"),
+				Description: "Nexus Dashboard login domain of the user, only used by NDFC",
+			},
+
 			"insecure": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Default:     true,
-				Description: "Allow insecure HTTPS client",
+				DefaultFunc: schema.EnvDefaultFunc("DCNM_INSECURE", false),
+				Description: "Skip the verification of the DCNM server certificate",
 			},
 
 			"proxy_url": &schema.Schema{
@@ -50,70 +107,288 @@ func Provider() *schema.Provider {
 				Default:     900000,
 				Description: "Expiration time in miliseconds for DCNM server",
 			},
+
+			"token_cache_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DCNM_TOKEN_CACHE_FILE", nil),
+				Description: "File keeping the login token, shared by the provider instances instead of each logging in",
+			},
+
+			"max_concurrent_requests": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of requests sent to DCNM at the same time, 0 for no limit",
+			},
+
+			"requests_per_second": &schema.Schema{
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "Maximum rate of the requests sent to DCNM, 0 for no limit",
+			},
+
+			"max_retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of times a request is retried after a transient failure",
+			},
+
+			"retry_min_backoff": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      2,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Delay in seconds before the first retry, doubled on every following retry",
+			},
+
+			"retry_max_backoff": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Maximum delay in seconds between two retries",
+			},
+
+			"retry_status_codes": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				Description: "HTTP status codes which are retried. Defaults to 429, 500, 502, 503 and 504",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"dcnm_vrf":       resourceDCNMVRF(),
-			"dcnm_inventory": resourceDCNMInventroy(),
-			"dcnm_network":   resourceDCNMNetwork(),
-			"dcnm_interface": resourceDCNMInterface(),
-			"dcnm_rest":      resourceDCNMRest(),
+			"dcnm_vrf":                resourceDCNMVRF(),
+			"dcnm_inventory":          resourceDCNMInventroy(),
+			"dcnm_network":            resourceDCNMNetwork(),
+			"dcnm_interface":          resourceDCNMInterface(),
+			"dcnm_interfaces":         resourceDCNMInterfaces(),
+			"dcnm_rest":               resourceDCNMRest(),
+			"dcnm_fabric":             resourceDCNMFabric(),
+			"dcnm_vrf_attachment":     resourceDCNMVRFAttachment(),
+			"dcnm_network_attachment": resourceDCNMNetworkAttachment(),
+			"dcnm_deployment":         resourceDCNMDeployment(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"dcnm_vrf":       datasourceDCNMVRF(),
-			"dcnm_inventory": datasourceDCNMInventory(),
-			"dcnm_network":   datasourceDCNMNetwork(),
-			"dcnm_interface": datasourceDCNMInterface(),
+			"dcnm_vrf":        datasourceDCNMVRF(),
+			"dcnm_vrfs":       datasourceDCNMVRFs(),
+			"dcnm_inventory":  datasourceDCNMInventory(),
+			"dcnm_switches":   datasourceDCNMSwitches(),
+			"dcnm_network":    datasourceDCNMNetwork(),
+			"dcnm_networks":   datasourceDCNMNetworks(),
+			"dcnm_interface":  datasourceDCNMInterface(),
+			"dcnm_interfaces": datasourceDCNMInterfaces(),
+			"dcnm_fabric":     datasourceDCNMFabric(),
+			"dcnm_rest":       datasourceDCNMRest(),
 		},
 
-		ConfigureFunc: configClient,
+		ConfigureContextFunc: configClient,
 	}
 }
 
-func configClient(d *schema.ResourceData) (interface{}, error) {
+func configClient(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	config := Config{
 		Username:   d.Get("username").(string),
 		Password:   d.Get("password").(string),
+		Token:      d.Get("token").(string),
 		URL:        d.Get("url").(string),
+		Platform:   d.Get("platform").(string),
+		Domain:     d.Get("login_domain").(string),
 		IsInsecure: d.Get("insecure").(bool),
+		CACert:     d.Get("ca_cert").(string),
+		ClientCert: d.Get("client_cert").(string),
+		ClientKey:  d.Get("client_key").(string),
 		ProxyURL:   d.Get("proxy_url").(string),
 		Expiry:     d.Get("expiry").(int),
+		TokenCache: d.Get("token_cache_file").(string),
+
+		MaxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+		RequestsPerSecond:     d.Get("requests_per_second").(float64),
+
+		MaxRetries: d.Get("max_retries").(int),
+		MinBackoff: d.Get("retry_min_backoff").(int),
+		MaxBackoff: d.Get("retry_max_backoff").(int),
+	}
+
+	if codes, ok := d.GetOk("retry_status_codes"); ok {
+		for _, code := range codes.(*schema.Set).List() {
+			config.RetryStatusCodes = append(config.RetryStatusCodes, code.(int))
+		}
+	} else {
+		config.RetryStatusCodes = defaultRetryStatusCodes
 	}
 
 	if err := config.Valid(); err != nil {
-		return nil, err
+		return nil, diag.FromErr(err)
 	}
 
-	return config.getClient(), nil
+	dcnmClient, err := config.getClient()
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	return dcnmClient, nil
 }
 
 func (c Config) Valid() error {
 
-	if c.Username == "" {
-		return fmt.Errorf("Username must be provided for the DCNM provider")
+	// the username and password are only needed without any other way to
+	// authenticate.
+	if c.Token == "" && c.ClientCert == "" {
+		if c.Username == "" {
+			return fmt.Errorf("Username must be provided for the DCNM provider, unless token or client_cert is set")
+		}
+
+		if c.Password == "" {
+			return fmt.Errorf("Password must be provided for the DCNM provider")
+		}
 	}
 
-	if c.Password == "" {
-		return fmt.Errorf("Password must be provided for the DCNM provider")
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return fmt.Errorf("client_cert and client_key must be provided together")
 	}
 
 	if c.URL == "" {
 		return fmt.Errorf("The URL must be provided for the DCNM provider")
 	}
 
+	if c.MinBackoff > c.MaxBackoff {
+		return fmt.Errorf("retry_min_backoff must not be greater than retry_max_backoff")
+	}
+
 	return nil
 }
 
-func (c Config) getClient() interface{} {
-	return client.GetClient(c.URL, c.Username, c.Password, int64(c.Expiry), client.Insecure(c.IsInsecure), client.ProxyUrl(c.ProxyURL))
+// readPEM returns the PEM encoded value, which is read from the file it names
+// when it is not PEM itself.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return ioutil.ReadFile(value)
+}
+
+func (c Config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.IsInsecure,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if c.CACert != "" {
+		caCert, err := readPEM(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca_cert : %s", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("ca_cert does not contain any PEM encoded certificate")
+		}
+	}
+
+	if c.ClientCert != "" {
+		clientCert, err := readPEM(c.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client_cert : %s", err)
+		}
+		clientKey, err := readPEM(c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client_key : %s", err)
+		}
+
+		cert, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client_cert and client_key : %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (c Config) getClient() (*Client, error) {
+	baseURL, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	retry := retryPolicy{
+		MaxRetries:  c.MaxRetries,
+		MinBackoff:  time.Duration(c.MinBackoff) * time.Second,
+		MaxBackoff:  time.Duration(c.MaxBackoff) * time.Second,
+		StatusCodes: make(map[int]bool),
+	}
+	for _, code := range c.RetryStatusCodes {
+		retry.StatusCodes[code] = true
+	}
+
+	platform := c.Platform
+	if platform == "" {
+		platform = platformDCNM
+	}
+
+	dcnmClient := &Client{clientState: &clientState{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Transport: transport},
+		platform:   platform,
+		username:   c.Username,
+		password:   c.Password,
+		domain:     c.Domain,
+		expiry:     int64(c.Expiry),
+		token:      c.Token,
+		retry:      retry,
+		throttle:   newThrottle(c.MaxConcurrentRequests, c.RequestsPerSecond),
+	}}
+	if c.TokenCache != "" {
+		dcnmClient.tokenCache = newTokenCache(c.TokenCache, c)
+	}
+	return dcnmClient, nil
 }
 
 type Config struct {
 	Username   string
 	Password   string
+	Token      string
 	URL        string
+	Platform   string
+	Domain     string
 	IsInsecure bool
+	CACert     string
+	ClientCert string
+	ClientKey  string
 	ProxyURL   string
 	Expiry     int
+	TokenCache string
+
+	MaxConcurrentRequests int
+	RequestsPerSecond     float64
+
+	MaxRetries       int
+	MinBackoff       int
+	MaxBackoff       int
+	RetryStatusCodes []int
 }
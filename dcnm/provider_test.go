@@ -1,11 +1,14 @@
 package dcnm
 
 import (
+	"context"
+	"net/http"
 	"os"
 	"sync"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 var testAccProviders map[string]*schema.Provider
@@ -61,13 +64,37 @@ func TestProvider_impl(t *testing.T) {
 func testAccPreCheck(t *testing.T) {
 	// We will use this function later on to make sure our test environment is valid.
 	// For example, you can make sure here that some environment variables are set.
-	if v := os.Getenv("DCNM_USERNAME"); v == "" {
-		t.Fatal("DCNM_USERNAME env variable must be set for acceptance tests")
-	}
-	if v := os.Getenv("DCNM_PASSWORD"); v == "" {
-		t.Fatal("DCNM_PASSWORD env variable must be set for acceptance tests")
+	if os.Getenv("DCNM_TOKEN") == "" && os.Getenv("DCNM_CLIENT_CERT") == "" {
+		if v := os.Getenv("DCNM_USERNAME"); v == "" {
+			t.Fatal("DCNM_USERNAME env variable must be set for acceptance tests, unless DCNM_TOKEN or DCNM_CLIENT_CERT is set")
+		}
+		if v := os.Getenv("DCNM_PASSWORD"); v == "" {
+			t.Fatal("DCNM_PASSWORD env variable must be set for acceptance tests")
+		}
 	}
 	if v := os.Getenv("DCNM_URL"); v == "" {
 		t.Fatal("DCNM_URL env variable must be set for acceptance tests")
 	}
 }
+
+func TestProvider_InsecureEnv(t *testing.T) {
+	defer os.Unsetenv("DCNM_INSECURE")
+
+	for env, insecure := range map[string]bool{"": false, "true": true} {
+		os.Setenv("DCNM_INSECURE", env)
+
+		p := Provider()
+		diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+			"url":   "https://dcnm.example.com",
+			"token": "token",
+		}))
+		if diags.HasError() {
+			t.Fatalf("err : %v", diags)
+		}
+
+		transport := p.Meta().(*Client).httpClient.Transport.(*http.Transport)
+		if transport.TLSClientConfig.InsecureSkipVerify != insecure {
+			t.Fatalf("DCNM_INSECURE=%q : expected insecure %t", env, insecure)
+		}
+	}
+}
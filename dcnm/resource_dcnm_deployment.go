@@ -0,0 +1,330 @@
+package dcnm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ciscoecosystem/dcnm-go-client/models"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type deployPayload map[string]interface{}
+
+func (payload deployPayload) ToMap() (map[string]interface{}, error) {
+	return payload, nil
+}
+
+func resourceDCNMDeployment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDCNMDeploymentCreate,
+		ReadContext:   resourceDCNMDeploymentRead,
+		UpdateContext: resourceDCNMDeploymentUpdate,
+		DeleteContext: resourceDCNMDeploymentDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"fabric_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"switches": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"interfaces": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"serial_number": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+
+			"vrfs": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"networks": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"triggers": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func getInterfaceType(serialNum string) string {
+	if strings.Contains(serialNum, "~") {
+		return "vpc"
+	}
+	return ""
+}
+
+// configuredObjects returns all of the configured objects of the given kind.
+func configuredObjects(d *schema.ResourceData, key string) *schema.Set {
+	return d.Get(key).(*schema.Set)
+}
+
+// outOfSyncObjects returns the configured objects of the given kind which
+// are missing from the state, as Read drops the ones which are out of sync.
+func outOfSyncObjects(d *schema.ResourceData, key string) *schema.Set {
+	o, n := d.GetChange(key)
+	return n.(*schema.Set).Difference(o.(*schema.Set))
+}
+
+// deployObjects deploys the objects returned by objects, with one request per
+// kind of object. Switches are deployed first and VRFs before networks, each
+// kind being in sync before the next one is deployed.
+func deployObjects(ctx context.Context, d *schema.ResourceData, m interface{}, timeout time.Duration, objects func(*schema.ResourceData, string) *schema.Set) error {
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+	deadline := time.Now().Add(timeout)
+
+	if switches := interfaceToStrList(objects(d, "switches").List()); len(switches) > 0 {
+		err := deploySwitches(ctx, dcnmClient, fabricName, switches, time.Until(deadline))
+		if err != nil {
+			return err
+		}
+	}
+
+	if interfaces := objects(d, "interfaces").List(); len(interfaces) > 0 {
+		intfDeploy := make([]models.Model, 0, len(interfaces))
+		for _, val := range interfaces {
+			intf := val.(map[string]interface{})
+			intfDeploy = append(intfDeploy, &models.InterfaceDelete{
+				SerialNumber: intf["serial_number"].(string),
+				Name:         intf["name"].(string),
+			})
+		}
+
+		_, err := dcnmClient.SaveList("/rest/interface/deploy", intfDeploy)
+		if err != nil {
+			return err
+		}
+
+		err = waitForDeployment(ctx, time.Until(deadline), func() (bool, error) {
+			for _, val := range interfaces {
+				intf := val.(map[string]interface{})
+				serialNum := intf["serial_number"].(string)
+				done, err := checkIntfDeploy(dcnmClient, serialNum, intf["name"].(string), getInterfaceType(serialNum))
+				if err != nil || !done {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if vrfs := interfaceToStrList(objects(d, "vrfs").List()); len(vrfs) > 0 {
+		err := deployVRF(dcnmClient, fabricName, strings.Join(vrfs, ","))
+		if err != nil {
+			return err
+		}
+
+		err = waitForDeployment(ctx, time.Until(deadline), func() (bool, error) {
+			for _, vrf := range vrfs {
+				done, err := checkVRFDeployDone(dcnmClient, fabricName, vrf)
+				if err != nil || !done {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if networks := interfaceToStrList(objects(d, "networks").List()); len(networks) > 0 {
+		err := deployNetworks(dcnmClient, fabricName, networks)
+		if err != nil {
+			return err
+		}
+
+		err = waitForDeployment(ctx, time.Until(deadline), func() (bool, error) {
+			for _, network := range networks {
+				done, err := checkNetworkDeployDone(dcnmClient, fabricName, network)
+				if err != nil || !done {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceDCNMDeploymentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Create method ")
+
+	err := deployObjects(ctx, d, m, d.Timeout(schema.TimeoutCreate), configuredObjects)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(resource.PrefixedUniqueId(d.Get("fabric_name").(string) + ":"))
+
+	log.Println("[DEBUG] End of Create method ", d.Id())
+	return resourceDCNMDeploymentRead(ctx, d, m)
+}
+
+func resourceDCNMDeploymentUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Update method ", d.Id())
+
+	if d.HasChanges("switches", "interfaces", "vrfs", "networks") {
+		err := deployObjects(ctx, d, m, d.Timeout(schema.TimeoutUpdate), outOfSyncObjects)
+		if err != nil {
+			d.Partial(true)
+			return diag.FromErr(err)
+		}
+	}
+
+	log.Println("[DEBUG] End of Update method ", d.Id())
+	return resourceDCNMDeploymentRead(ctx, d, m)
+}
+
+// resourceDCNMDeploymentRead keeps only the objects which are still deployed,
+// so the ones changed since are deployed again on the next apply. Objects
+// which no longer exist or failed to deploy count as not deployed.
+func resourceDCNMDeploymentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+
+	_, err := getRemoteFabric(dcnmClient, fabricName)
+	if err != nil {
+		if isNotFound(err) {
+			log.Printf("[WARN] Fabric %s not found, removing deployment from state", fabricName)
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	if switches := d.Get("switches").(*schema.Set).List(); len(switches) > 0 {
+		statuses, err := getSwitchConfigStatuses(dcnmClient, fabricName)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		deployed := make([]interface{}, 0, len(switches))
+		for _, serialNum := range switches {
+			if statuses[serialNum.(string)] == "In-Sync" {
+				deployed = append(deployed, serialNum)
+			}
+		}
+		d.Set("switches", deployed)
+	}
+
+	if interfaces := d.Get("interfaces").(*schema.Set).List(); len(interfaces) > 0 {
+		deployed := make([]interface{}, 0, len(interfaces))
+		for _, val := range interfaces {
+			intf := val.(map[string]interface{})
+			serialNum := intf["serial_number"].(string)
+			done, err := checkIntfDeploy(dcnmClient, serialNum, intf["name"].(string), getInterfaceType(serialNum))
+			if err != nil && !isNotFound(err) && !isDeployFailure(err) {
+				return diag.FromErr(err)
+			}
+			if done {
+				deployed = append(deployed, intf)
+			}
+		}
+		d.Set("interfaces", deployed)
+	}
+
+	if vrfs := d.Get("vrfs").(*schema.Set).List(); len(vrfs) > 0 {
+		deployed := make([]interface{}, 0, len(vrfs))
+		for _, vrf := range vrfs {
+			done, err := checkVRFDeployDone(dcnmClient, fabricName, vrf.(string))
+			if err != nil && !isNotFound(err) && !isDeployFailure(err) {
+				return diag.FromErr(err)
+			}
+			if done {
+				deployed = append(deployed, vrf)
+			}
+		}
+		d.Set("vrfs", deployed)
+	}
+
+	if networks := d.Get("networks").(*schema.Set).List(); len(networks) > 0 {
+		deployed := make([]interface{}, 0, len(networks))
+		for _, network := range networks {
+			done, err := checkNetworkDeployDone(dcnmClient, fabricName, network.(string))
+			if err != nil && !isNotFound(err) && !isDeployFailure(err) {
+				return diag.FromErr(err)
+			}
+			if done {
+				deployed = append(deployed, network)
+			}
+		}
+		d.Set("networks", deployed)
+	}
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
+
+// isDeployFailure reports whether err means DCNM failed to deploy the object.
+func isDeployFailure(err error) bool {
+	var failed deployFailedError
+	return errors.As(err, &failed)
+}
+
+func resourceDCNMDeploymentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Delete method ", d.Id())
+
+	// deployments can not be reverted, the objects are undeployed when they
+	// are detached or deleted themselves.
+	d.SetId("")
+
+	log.Println("[DEBUG] End of Delete method ")
+	return nil
+}
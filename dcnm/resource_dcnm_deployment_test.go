@@ -0,0 +1,213 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+var providerDeployment *schema.Provider
+
+func TestAccDCNMDeployment_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactoriesInternal(&providerDeployment),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckDCNMDeploymentConfig_basic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDCNMDeploymentDeployed("dcnm_deployment.test"),
+					resource.TestCheckResourceAttr("dcnm_deployment.test", "vrfs.#", "1"),
+					resource.TestCheckResourceAttr("dcnm_deployment.test", "networks.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDCNMDeploymentConfig_basic() string {
+	return `
+	resource "dcnm_vrf" "test" {
+		fabric_name = "fab2"
+		name        = "tf_vrf_deploy"
+		vlan_id     = 2002
+		deploy      = false
+	}
+
+	resource "dcnm_vrf_attachment" "test" {
+		fabric_name   = dcnm_vrf.test.fabric_name
+		vrf_name      = dcnm_vrf.test.name
+		serial_number = "9ZGMF8CBZK5"
+		deploy        = false
+	}
+
+	resource "dcnm_network" "test" {
+		fabric_name = "fab2"
+		name        = "tf_net_deploy"
+		vrf_name    = dcnm_vrf.test.name
+		vlan_id     = 2300
+		deploy      = false
+	}
+
+	resource "dcnm_network_attachment" "test" {
+		fabric_name   = dcnm_network.test.fabric_name
+		network_name  = dcnm_network.test.name
+		serial_number = "9ZGMF8CBZK5"
+		switch_ports  = ["Ethernet1/5"]
+		deploy        = false
+	}
+
+	resource "dcnm_deployment" "test" {
+		fabric_name = "fab2"
+		vrfs        = [dcnm_vrf_attachment.test.vrf_name]
+		networks    = [dcnm_network_attachment.test.network_name]
+	}
+	`
+}
+
+func testAccCheckDCNMDeploymentDeployed(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+
+		if !ok {
+			return fmt.Errorf("Deployment %s not found", name)
+		}
+
+		dcnmClient := (*providerDeployment).Meta().(*Client)
+
+		attrs := rs.Primary.Attributes
+		done, err := checkNetworkDeployDone(dcnmClient, attrs["fabric_name"], "tf_net_deploy")
+		if err != nil {
+			return err
+		}
+		if !done {
+			return fmt.Errorf("Network is not deployed")
+		}
+		return nil
+	}
+}
+
+func TestDCNMDeployment_MockCRUD(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMDeployment()
+	rNetAttach := resourceDCNMNetworkAttachment()
+	rIntf := resourceDCNMInterface()
+
+	serial := testMockSerial(t, dcnmClient, "leaf1")
+
+	netStates := make(map[string]*terraform.InstanceState)
+	netAttachStates := make(map[string]*terraform.InstanceState)
+	netAttachRaws := make(map[string]map[string]interface{})
+	for _, name := range []string{"mock_net_batch1", "mock_net_batch2"} {
+		netStates[name] = testMockApply(t, resourceDCNMNetwork(), nil, map[string]interface{}{
+			"fabric_name": "fab1",
+			"name":        name,
+			"vlan_id":     2220,
+			"deploy":      false,
+		}, dcnmClient)
+
+		netAttachRaws[name] = map[string]interface{}{
+			"fabric_name":   "fab1",
+			"network_name":  name,
+			"serial_number": serial,
+			"switch_ports":  []interface{}{"Ethernet1/1"},
+			"deploy":        false,
+		}
+		netAttachStates[name] = testMockApply(t, rNetAttach, nil, netAttachRaws[name], dcnmClient)
+	}
+
+	rawIntf := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "loopback110",
+		"type":          "loopback",
+		"policy":        "int_loopback_11_1",
+		"switch_name_1": "leaf1",
+		"ipv4":          "10.10.11.1",
+		"deploy":        false,
+	}
+	intfState := testMockApply(t, rIntf, nil, rawIntf, dcnmClient)
+	testMockCheckAttr(t, intfState, "deploy", "false")
+
+	netDeploys := testMockServer.callCount("POST", "/rest/top-down/fabrics/fab1/networks/deployments")
+	intfDeploys := testMockServer.callCount("POST", "/rest/interface/deploy")
+
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"networks":    []interface{}{"mock_net_batch1", "mock_net_batch2"},
+		"interfaces": []interface{}{
+			map[string]interface{}{
+				"serial_number": serial,
+				"name":          "loopback110",
+			},
+		},
+	}
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	if !strings.HasPrefix(state.ID, "fab1:") {
+		t.Fatalf("expected id prefixed with the fabric name, got : %s", state.ID)
+	}
+	testMockCheckAttr(t, state, "networks.#", "2")
+	testMockCheckAttr(t, state, "interfaces.#", "1")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	if got := testMockServer.callCount("POST", "/rest/top-down/fabrics/fab1/networks/deployments") - netDeploys; got != 1 {
+		t.Fatalf("expected one network deployment request, got %d", got)
+	}
+	if got := testMockServer.callCount("POST", "/rest/interface/deploy") - intfDeploys; got != 1 {
+		t.Fatalf("expected one interface deployment request, got %d", got)
+	}
+	for name := range netStates {
+		if got := testMockServer.callCount("POST", fmt.Sprintf("/rest/top-down/fabrics/fab1/networks/%s/deploy", name)); got != 0 {
+			t.Fatalf("expected network %s not to be deployed on its own, got %d requests", name, got)
+		}
+	}
+
+	// the resources opting out of deploying themselves keep deploy=false
+	// once the deployment is done.
+	for name, netAttachState := range netAttachStates {
+		netAttachStates[name] = testMockPlanEmpty(t, rNetAttach, netAttachState, netAttachRaws[name], dcnmClient)
+		testMockCheckAttr(t, netAttachStates[name], "deploy", "false")
+	}
+	intfState = testMockPlanEmpty(t, rIntf, intfState, rawIntf, dcnmClient)
+
+	// a changed attachment is pending again, which the deployment picks up
+	// on the next plan.
+	netAttachRaws["mock_net_batch2"]["switch_ports"] = []interface{}{"Ethernet1/1", "Ethernet1/2"}
+	netAttachStates["mock_net_batch2"] = testMockApply(t, rNetAttach, netAttachStates["mock_net_batch2"], netAttachRaws["mock_net_batch2"], dcnmClient)
+
+	state = testMockRefresh(t, r, state, dcnmClient)
+	testMockCheckAttr(t, state, "networks.#", "1")
+
+	// only the objects found out of sync are deployed again.
+	netDeploys = testMockServer.callCount("POST", "/rest/top-down/fabrics/fab1/networks/deployments")
+	intfDeploys = testMockServer.callCount("POST", "/rest/interface/deploy")
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "networks.#", "2")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	if got := testMockServer.callCount("POST", "/rest/top-down/fabrics/fab1/networks/deployments") - netDeploys; got != 1 {
+		t.Fatalf("expected one network deployment request, got %d", got)
+	}
+	if got := testMockServer.callCount("POST", "/rest/interface/deploy") - intfDeploys; got != 0 {
+		t.Fatalf("expected the deployed interface not to be deployed again, got %d requests", got)
+	}
+
+	// failures to check the deployment are reported instead of dropping
+	// the object from the state.
+	testMockServer.failPath("/rest/top-down/fabrics/fab1/networks/mock_net_batch1/attachments", 1, http.StatusBadRequest, "Invalid request")
+	_, diags := r.RefreshWithoutUpgrade(context.Background(), state, dcnmClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "Invalid request") {
+		t.Fatalf("expected the status check error to be returned, got : %v", diags)
+	}
+
+	testMockDestroy(t, r, state, dcnmClient)
+	testMockDestroy(t, rIntf, intfState, dcnmClient)
+	for name, netAttachState := range netAttachStates {
+		testMockDestroy(t, rNetAttach, netAttachState, dcnmClient)
+		testMockDestroy(t, resourceDCNMNetwork(), netStates[name], dcnmClient)
+	}
+}
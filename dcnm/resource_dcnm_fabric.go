@@ -0,0 +1,315 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// fabricAttributeKeys maps the first-class fabric attributes to the nvPairs
+// keys of the Easy_Fabric template.
+var fabricAttributeKeys = map[string]string{
+	"bgp_asn":                "BGP_AS",
+	"replication_mode":       "REPLICATION_MODE",
+	"underlay_routing":       "LINK_STATE_ROUTING",
+	"loopback0_ip_range":     "LOOPBACK0_IP_RANGE",
+	"loopback1_ip_range":     "LOOPBACK1_IP_RANGE",
+	"subnet_range":           "SUBNET_RANGE",
+	"anycast_rp_ip_range":    "ANYCAST_RP_IP_RANGE",
+	"multicast_group_subnet": "MULTICAST_GROUP_SUBNET",
+	"anycast_gw_mac":         "ANYCAST_GW_MAC",
+	"fabric_mtu":             "FABRIC_MTU",
+}
+
+type fabricNVPairs map[string]interface{}
+
+func (nvPairs fabricNVPairs) ToMap() (map[string]interface{}, error) {
+	return nvPairs, nil
+}
+
+func resourceDCNMFabric() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDCNMFabricCreate,
+		UpdateContext: resourceDCNMFabricUpdate,
+		ReadContext:   resourceDCNMFabricRead,
+		DeleteContext: resourceDCNMFabricDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDCNMFabricImporter,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"template": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "Easy_Fabric",
+			},
+
+			"bgp_asn": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"replication_mode": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"Multicast",
+					"Ingress",
+				}, false),
+			},
+
+			"underlay_routing": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"ospf",
+					"is-is",
+				}, false),
+			},
+
+			"loopback0_ip_range": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"loopback1_ip_range": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"subnet_range": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"anycast_rp_ip_range": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"multicast_group_subnet": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"anycast_gw_mac": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"fabric_mtu": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"nv_pairs": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"fabric_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func getRemoteFabric(client *Client, name string) (*container.Container, error) {
+	durl := fmt.Sprintf("/rest/control/fabrics/%s", name)
+
+	cont, err := client.GetviaURL(durl)
+	if err != nil {
+		return nil, err
+	}
+
+	return cont, nil
+}
+
+func setFabricAttributes(d *schema.ResourceData, cont *container.Container) *schema.ResourceData {
+	d.Set("name", stripQuotes(cont.S("fabricName").String()))
+	d.Set("template", stripQuotes(cont.S("templateName").String()))
+
+	if id, err := strconv.Atoi(stripQuotes(cont.S("id").String())); err == nil {
+		d.Set("fabric_id", id)
+	}
+
+	nvPairs := cont.S("nvPairs")
+	for attr, key := range fabricAttributeKeys {
+		if nvPairs.Exists(key) {
+			d.Set(attr, stripQuotes(nvPairs.S(key).String()))
+		}
+	}
+
+	// only the keys managed through nv_pairs are tracked, the template has
+	// hundreds of parameters which would otherwise always show a diff.
+	nvGet := make(map[string]interface{})
+	for key := range d.Get("nv_pairs").(map[string]interface{}) {
+		if nvPairs.Exists(key) {
+			nvGet[key] = stripQuotes(nvPairs.S(key).String())
+		}
+	}
+	d.Set("nv_pairs", nvGet)
+
+	d.SetId(stripQuotes(cont.S("fabricName").String()))
+	return d
+}
+
+func getFabricNVPairs(d *schema.ResourceData) fabricNVPairs {
+	nvPairs := make(fabricNVPairs)
+
+	if extra, ok := d.GetOk("nv_pairs"); ok {
+		for key, val := range extra.(map[string]interface{}) {
+			nvPairs[key] = val.(string)
+		}
+	}
+
+	for attr, key := range fabricAttributeKeys {
+		if val, ok := d.GetOk(attr); ok {
+			nvPairs[key] = val.(string)
+		}
+	}
+	nvPairs["FABRIC_NAME"] = d.Get("name").(string)
+
+	return nvPairs
+}
+
+func resourceDCNMFabricImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	log.Println("[DEBUG] Begining Importer ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	cont, err := getRemoteFabric(dcnmClient, d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	stateImport := setFabricAttributes(d, cont)
+
+	log.Println("[DEBUG] End of Importer ", d.Id())
+	return []*schema.ResourceData{stateImport}, nil
+}
+
+func resourceDCNMFabricCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Create method ")
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	name := d.Get("name").(string)
+	template := d.Get("template").(string)
+
+	nvPairs := getFabricNVPairs(d)
+
+	durl := fmt.Sprintf("/rest/control/fabrics/%s/%s", name, template)
+	_, err := dcnmClient.Save(durl, nvPairs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(name)
+
+	log.Println("[DEBUG] End of Create method ", d.Id())
+	return resourceDCNMFabricRead(ctx, d, m)
+}
+
+func resourceDCNMFabricUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Update method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	name := d.Get("name").(string)
+	template := d.Get("template").(string)
+
+	// DCNM expects the complete set of template parameters on update, so the
+	// configured values are layered on top of the current fabric settings.
+	cont, err := getRemoteFabric(dcnmClient, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	nvPairs := make(fabricNVPairs)
+	if remote, ok := cont.S("nvPairs").Data().(map[string]interface{}); ok {
+		for key, val := range remote {
+			nvPairs[key] = val
+		}
+	}
+	for key, val := range getFabricNVPairs(d) {
+		nvPairs[key] = val
+	}
+
+	durl := fmt.Sprintf("/rest/control/fabrics/%s/%s", name, template)
+	_, err = dcnmClient.Update(durl, nvPairs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(name)
+
+	log.Println("[DEBUG] End of Update method ", d.Id())
+	return resourceDCNMFabricRead(ctx, d, m)
+}
+
+func resourceDCNMFabricRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	cont, err := getRemoteFabric(dcnmClient, d.Id())
+	if err != nil {
+		if isNotFound(err) {
+			log.Printf("[WARN] Fabric %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	setFabricAttributes(d, cont)
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
+
+func resourceDCNMFabricDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Delete method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	durl := fmt.Sprintf("/rest/control/fabrics/%s", d.Id())
+	_, err := dcnmClient.Delete(durl)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	log.Println("[DEBUG] End of Delete method ")
+	return nil
+}
@@ -0,0 +1,169 @@
+package dcnm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+var providerfFabric *schema.Provider
+
+func TestAccDCNMFabric_Basic(t *testing.T) {
+	var asn string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactoriesInternal(&providerfFabric),
+		CheckDestroy:      testAccCheckDCNMFabricDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckDCNMFabricConfig_basic("65001"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDCNMFabricExists("dcnm_fabric.test", &asn),
+					testAccCheckDCNMFabricAttributes("65001", &asn),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDCNMFabric_Update(t *testing.T) {
+	var asn string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactoriesInternal(&providerfFabric),
+		CheckDestroy:      testAccCheckDCNMFabricDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckDCNMFabricConfig_basic("65001"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDCNMFabricExists("dcnm_fabric.test", &asn),
+					testAccCheckDCNMFabricAttributes("65001", &asn),
+				),
+			},
+			{
+				Config: testAccCheckDCNMFabricConfig_basic("65002"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDCNMFabricExists("dcnm_fabric.test", &asn),
+					testAccCheckDCNMFabricAttributes("65002", &asn),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDCNMFabricConfig_basic(asn string) string {
+	return fmt.Sprintf(`
+	resource "dcnm_fabric" "test" {
+		name             = "tf_fabric"
+		bgp_asn          = "%s"
+		replication_mode = "Ingress"
+	}
+	`, asn)
+}
+
+func testAccCheckDCNMFabricExists(name string, asn *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+
+		if !ok {
+			return fmt.Errorf("Fabric %s not found", name)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No Fabric dn was set")
+		}
+
+		dcnmClient := (*providerfFabric).Meta().(*Client)
+
+		cont, err := dcnmClient.GetviaURL(fmt.Sprintf("/rest/control/fabrics/%s", rs.Primary.ID))
+		if err != nil {
+			return err
+		}
+
+		*asn = stripQuotes(cont.S("nvPairs", "BGP_AS").String())
+		return nil
+	}
+}
+
+func testAccCheckDCNMFabricDestroy(s *terraform.State) error {
+	dcnmClient := (*providerfFabric).Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type == "dcnm_fabric" {
+			_, err := dcnmClient.GetviaURL(fmt.Sprintf("/rest/control/fabrics/%s", rs.Primary.ID))
+			if err == nil {
+				return fmt.Errorf("Fabric still exists")
+			}
+		}
+	}
+
+	return nil
+}
+
+func testAccCheckDCNMFabricAttributes(asnExp string, asn *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if asnExp != *asn {
+			return fmt.Errorf("Bad Fabric BGP ASN %s", *asn)
+		}
+		return nil
+	}
+}
+
+func TestDCNMFabric_MockCRUD(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMFabric()
+
+	raw := map[string]interface{}{
+		"name":             "mock_fabric",
+		"bgp_asn":          "65001",
+		"replication_mode": "Ingress",
+		"nv_pairs": map[string]interface{}{
+			"VRF_VLAN_RANGE": "2000-2299",
+		},
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "id", "mock_fabric")
+	testMockCheckAttr(t, state, "template", "Easy_Fabric")
+	testMockCheckAttr(t, state, "nv_pairs.VRF_VLAN_RANGE", "2000-2299")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["bgp_asn"] = "65002"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "bgp_asn", "65002")
+	testMockCheckAttr(t, state, "replication_mode", "Ingress")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	imported := testMockImport(t, r, "mock_fabric", dcnmClient)
+	testMockCheckAttr(t, imported, "bgp_asn", "65002")
+
+	data := testMockReadData(t, datasourceDCNMFabric(), map[string]interface{}{"name": "mock_fabric"}, dcnmClient)
+	testMockCheckAttr(t, data, "replication_mode", "Ingress")
+	testMockCheckAttr(t, data, "nv_pairs.BGP_AS", "65002")
+
+	testMockDestroy(t, r, state, dcnmClient)
+	if _, err := getRemoteFabric(dcnmClient, "mock_fabric"); err == nil {
+		t.Fatalf("Fabric still exists")
+	}
+}
+
+func TestDCNMFabric_MockDeletedOutOfBand(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMFabric()
+
+	raw := map[string]interface{}{
+		"name":    "mock_fabric_gone",
+		"bgp_asn": "65003",
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	if _, err := dcnmClient.Delete("/rest/control/fabrics/mock_fabric_gone"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	testMockRefreshGone(t, r, state, dcnmClient)
+}
@@ -1,27 +1,41 @@
 package dcnm
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/container"
 	"github.com/ciscoecosystem/dcnm-go-client/models"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// defaultEthernetPolicy is the policy ethernet ports are reset to when they
+// leave Terraform, since they can not be deleted from the switch.
+const defaultEthernetPolicy = "int_trunk_host_11_1"
+
 func resourceDCNMInterface() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceDCNMInterfaceCreate,
-		Update: resourceDCNMInterfaceUpdate,
-		Read:   resourceDCNMInterfaceRead,
-		Delete: resourceDCNMInterfaceDelete,
+		CreateContext: resourceDCNMInterfaceCreate,
+		UpdateContext: resourceDCNMInterfaceUpdate,
+		ReadContext:   resourceDCNMInterfaceRead,
+		DeleteContext: resourceDCNMInterfaceDelete,
+		CustomizeDiff: resourceDCNMInterfaceCustomizeDiff,
 
 		Importer: &schema.ResourceImporter{
-			State: resourceDCNMInterfaceImporter,
+			StateContext: resourceDCNMInterfaceImporter,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -53,12 +67,18 @@ func resourceDCNMInterface() *schema.Resource {
 					"loopback",
 					"sub-interface",
 					"ethernet",
+					"svi",
+					"nve",
+					"fex",
+					"breakout",
 				}, false),
 			},
 
+			// the policy is required for every type but breakout, which
+			// is not configured with a policy.
 			"policy": &schema.Schema{
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
 			},
 
 			"switch_name_1": &schema.Schema{
@@ -292,6 +312,25 @@ func resourceDCNMInterface() *schema.Resource {
 				}, false),
 			},
 
+			"nve_source_interface": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"fex_id": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntBetween(101, 199),
+			},
+
+			"breakout_map": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[0-9]+g-[0-9]x$`), "must be a breakout map such as 25g-4x"),
+			},
+
 			"serial_number": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
@@ -316,6 +355,30 @@ func resourceDCNMInterface() *schema.Resource {
 				Default:  true,
 			},
 
+			"nv_pairs": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			// ethernet ports are reset to these when the resource is deleted.
+			"default_policy": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  defaultEthernetPolicy,
+			},
+
+			"default_nv_pairs": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
 			"deploy": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -325,7 +388,7 @@ func resourceDCNMInterface() *schema.Resource {
 	}
 }
 
-func getRemoteInterface(client *client.Client, serialNum, name string) (*container.Container, error) {
+func getRemoteInterface(client *Client, serialNum, name string) (*container.Container, error) {
 	durl := fmt.Sprintf("/rest/interface?serialNumber=%s&ifName=%s", serialNum, name)
 	cont, err := client.GetviaURL(durl)
 	if err != nil {
@@ -334,10 +397,34 @@ func getRemoteInterface(client *client.Client, serialNum, name string) (*contain
 	return cont, nil
 }
 
+// resourceDCNMInterfaceCustomizeDiff makes sure a policy is set for the
+// interface types other than breakout, which have no policy.
+func resourceDCNMInterfaceCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, m interface{}) error {
+	if !diff.NewValueKnown("type") || diff.Get("type").(string) == "breakout" {
+		return nil
+	}
+
+	if _, ok := diff.GetOk("policy"); !ok && diff.NewValueKnown("policy") {
+		return fmt.Errorf("policy is required for %s interfaces", diff.Get("type").(string))
+	}
+	return nil
+}
+
 func setInterfaceAttributes(d *schema.ResourceData, cont *container.Container, intftype string) *schema.ResourceData {
 	d.Set("policy", stripQuotes(cont.S("policy").String()))
 
 	interfaces := cont.S("interfaces").Index(0)
+
+	// only the keys managed through nv_pairs are tracked, the other template
+	// parameters are covered by the attributes or left to the policy.
+	nvGet := make(map[string]interface{})
+	for key := range d.Get("nv_pairs").(map[string]interface{}) {
+		if interfaces.Exists("nvPairs", key) {
+			nvGet[key] = stripQuotes(interfaces.S("nvPairs", key).String())
+		}
+	}
+	d.Set("nv_pairs", nvGet)
+
 	d.Set("serial_number", stripQuotes(interfaces.S("serialNumber").String()))
 	d.Set("type", intftype)
 	d.Set("fabric_name", stripQuotes(interfaces.S("nvPairs", "FABRIC_NAME").String()))
@@ -459,20 +546,246 @@ func setInterfaceAttributes(d *schema.ResourceData, cont *container.Container, i
 		d.Set("allowed_vlans", stripQuotes(interfaces.S("nvPairs", "ALLOWED_VLANS").String()))
 		d.Set("configuration", stripQuotes(interfaces.S("nvPairs", "CONF").String()))
 		d.Set("description", stripQuotes(interfaces.S("nvPairs", "DESC").String()))
+		if interfaces.Exists("nvPairs", "INTF_VRF") {
+			d.Set("vrf", stripQuotes(interfaces.S("nvPairs", "INTF_VRF").String()))
+		}
 
 		d.Set("pc_interface", make([]interface{}, 0, 1))
 		d.Set("vpc_peer1_interface", make([]interface{}, 0, 1))
 		d.Set("vpc_peer2_interface", make([]interface{}, 0, 1))
 
+	} else if intftype == "svi" {
+
+		d.Set("vrf", stripQuotes(interfaces.S("nvPairs", "INTF_VRF").String()))
+		d.Set("ipv4", stripQuotes(interfaces.S("nvPairs", "IP").String()))
+		d.Set("ipv4_prefix", stripQuotes(interfaces.S("nvPairs", "PREFIX").String()))
+		d.Set("ipv6", stripQuotes(interfaces.S("nvPairs", "IPv6").String()))
+		d.Set("ipv6_prefix", stripQuotes(interfaces.S("nvPairs", "IPv6_PREFIX").String()))
+		d.Set("mtu", stripQuotes(interfaces.S("nvPairs", "MTU").String()))
+		d.Set("configuration", stripQuotes(interfaces.S("nvPairs", "CONF").String()))
+		d.Set("description", stripQuotes(interfaces.S("nvPairs", "DESC").String()))
+
+		d.Set("pc_interface", make([]interface{}, 0, 1))
+		d.Set("vpc_peer1_interface", make([]interface{}, 0, 1))
+		d.Set("vpc_peer2_interface", make([]interface{}, 0, 1))
+
+	} else if intftype == "nve" {
+
+		d.Set("nve_source_interface", stripQuotes(interfaces.S("nvPairs", "SOURCE_INTERFACE_NAME").String()))
+		d.Set("configuration", stripQuotes(interfaces.S("nvPairs", "CONF").String()))
+		d.Set("description", stripQuotes(interfaces.S("nvPairs", "DESC").String()))
+
+		d.Set("pc_interface", make([]interface{}, 0, 1))
+		d.Set("vpc_peer1_interface", make([]interface{}, 0, 1))
+		d.Set("vpc_peer2_interface", make([]interface{}, 0, 1))
+
+	} else if intftype == "fex" {
+
+		if fexID, err := strconv.Atoi(stripQuotes(interfaces.S("nvPairs", "FEX_ID").String())); err == nil {
+			d.Set("fex_id", fexID)
+		}
+		pcIntfAcc := interfaceToStrList(d.Get("pc_interface"))
+		pcIntfGet := stringToList(stripQuotes(interfaces.S("nvPairs", "MEMBER_INTERFACES").String()))
+		if compareStrLists(pcIntfAcc, pcIntfGet) {
+			d.Set("pc_interface", d.Get("pc_interface"))
+		} else {
+			d.Set("pc_interface", stringToList(stripQuotes(interfaces.S("nvPairs", "MEMBER_INTERFACES").String())))
+		}
+		d.Set("mtu", stripQuotes(interfaces.S("nvPairs", "MTU").String()))
+		d.Set("configuration", stripQuotes(interfaces.S("nvPairs", "CONF").String()))
+		d.Set("description", stripQuotes(interfaces.S("nvPairs", "DESC").String()))
+
+		d.Set("vpc_peer1_interface", make([]interface{}, 0, 1))
+		d.Set("vpc_peer2_interface", make([]interface{}, 0, 1))
+
 	}
 
 	return d
 }
 
-func resourceDCNMInterfaceImporter(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+// mergeInterfaceNVPairs sets the nv_pairs over the nvPairs built from the
+// attributes, so any parameter of the policy can be configured. Keys removed
+// from nv_pairs are sent empty, which resets them to the policy default,
+// unless an attribute sets them.
+func mergeInterfaceNVPairs(d *schema.ResourceData, nvPairMap map[string]interface{}) {
+	if d.HasChange("nv_pairs") {
+		o, n := d.GetChange("nv_pairs")
+		for key := range o.(map[string]interface{}) {
+			_, kept := n.(map[string]interface{})[key]
+			if _, set := nvPairMap[key]; !kept && !set {
+				nvPairMap[key] = ""
+			}
+		}
+	}
+
+	if extra, ok := d.GetOk("nv_pairs"); ok {
+		for key, val := range extra.(map[string]interface{}) {
+			nvPairMap[key] = val.(string)
+		}
+	}
+}
+
+func setEthernetNVPairs(d *schema.ResourceData, nvPairMap map[string]interface{}) {
+	if bpduF, ok := d.GetOk("bpdu_gaurd_flag"); ok {
+		nvPairMap["BPDUGUARD_ENABLED"] = bpduF.(string)
+	} else {
+		nvPairMap["BPDUGUARD_ENABLED"] = ""
+	}
+	if pff, ok := d.GetOk("port_fast_flag"); ok {
+		nvPairMap["PORTTYPE_FAST_ENABLED"] = pff.(bool)
+	}
+	if mtu, ok := d.GetOk("mtu"); ok {
+		nvPairMap["MTU"] = mtu.(string)
+	} else {
+		nvPairMap["MTU"] = ""
+	}
+	if speed, ok := d.GetOk("ethernet_speed"); ok {
+		nvPairMap["SPEED"] = speed.(string)
+	} else {
+		nvPairMap["SPEED"] = ""
+	}
+	if vlans, ok := d.GetOk("allowed_vlans"); ok {
+		nvPairMap["ALLOWED_VLANS"] = vlans.(string)
+	} else {
+		nvPairMap["ALLOWED_VLANS"] = ""
+	}
+	// the vrf is only a parameter of the routed policies.
+	if vrf, ok := d.GetOk("vrf"); ok {
+		nvPairMap["INTF_VRF"] = vrf.(string)
+	}
+	if ip, ok := d.GetOk("ipv4"); ok {
+		nvPairMap["IP"] = ip.(string)
+	} else {
+		nvPairMap["IP"] = ""
+	}
+	if ipv4Pre, ok := d.GetOk("ipv4_prefix"); ok {
+		nvPairMap["PREFIX"] = ipv4Pre.(string)
+	} else {
+		nvPairMap["PREFIX"] = ""
+	}
+	if ipv6, ok := d.GetOk("ipv6"); ok {
+		nvPairMap["IPv6"] = ipv6.(string)
+	} else {
+		nvPairMap["IPv6"] = ""
+	}
+	if ipv6Pre, ok := d.GetOk("ipv6_prefix"); ok {
+		nvPairMap["IPv6_PREFIX"] = ipv6Pre.(string)
+	} else {
+		nvPairMap["IPv6_PREFIX"] = ""
+	}
+	if accVlans, ok := d.GetOk("access_vlans"); ok {
+		nvPairMap["ACCESS_VLAN"] = accVlans.(string)
+	} else {
+		nvPairMap["ACCESS_VLAN"] = ""
+	}
+	if desc, ok := d.GetOk("description"); ok {
+		nvPairMap["DESC"] = desc.(string)
+	} else {
+		nvPairMap["DESC"] = ""
+	}
+	if conf, ok := d.GetOk("configuration"); ok {
+		nvPairMap["CONF"] = conf.(string)
+	} else {
+		nvPairMap["CONF"] = ""
+	}
+}
+
+func setSVINVPairs(d *schema.ResourceData, nvPairMap map[string]interface{}) {
+	if vrf, ok := d.GetOk("vrf"); ok {
+		nvPairMap["INTF_VRF"] = vrf.(string)
+	} else {
+		nvPairMap["INTF_VRF"] = ""
+	}
+	if ip, ok := d.GetOk("ipv4"); ok {
+		nvPairMap["IP"] = ip.(string)
+	} else {
+		nvPairMap["IP"] = ""
+	}
+	if ipv4Pre, ok := d.GetOk("ipv4_prefix"); ok {
+		nvPairMap["PREFIX"] = ipv4Pre.(string)
+	} else {
+		nvPairMap["PREFIX"] = ""
+	}
+	if ipv6, ok := d.GetOk("ipv6"); ok {
+		nvPairMap["IPv6"] = ipv6.(string)
+	} else {
+		nvPairMap["IPv6"] = ""
+	}
+	if ipv6Pre, ok := d.GetOk("ipv6_prefix"); ok {
+		nvPairMap["IPv6_PREFIX"] = ipv6Pre.(string)
+	} else {
+		nvPairMap["IPv6_PREFIX"] = ""
+	}
+	if mtu, ok := d.GetOk("mtu"); ok {
+		nvPairMap["MTU"] = mtu.(string)
+	} else {
+		nvPairMap["MTU"] = ""
+	}
+	if desc, ok := d.GetOk("description"); ok {
+		nvPairMap["DESC"] = desc.(string)
+	} else {
+		nvPairMap["DESC"] = ""
+	}
+	if conf, ok := d.GetOk("configuration"); ok {
+		nvPairMap["CONF"] = conf.(string)
+	} else {
+		nvPairMap["CONF"] = ""
+	}
+}
+
+func setNVENVPairs(d *schema.ResourceData, nvPairMap map[string]interface{}) {
+	if source, ok := d.GetOk("nve_source_interface"); ok {
+		nvPairMap["SOURCE_INTERFACE_NAME"] = source.(string)
+	} else {
+		nvPairMap["SOURCE_INTERFACE_NAME"] = ""
+	}
+	if desc, ok := d.GetOk("description"); ok {
+		nvPairMap["DESC"] = desc.(string)
+	} else {
+		nvPairMap["DESC"] = ""
+	}
+	if conf, ok := d.GetOk("configuration"); ok {
+		nvPairMap["CONF"] = conf.(string)
+	} else {
+		nvPairMap["CONF"] = ""
+	}
+}
+
+// setFEXNVPairs sets the parameters of a FEX, which is connected to the
+// switch through the port-channel name.
+func setFEXNVPairs(d *schema.ResourceData, nvPairMap map[string]interface{}, name string) {
+	nvPairMap["PO_ID"] = name
+	if fexID, ok := d.GetOk("fex_id"); ok {
+		nvPairMap["FEX_ID"] = fexID.(int)
+	} else {
+		nvPairMap["FEX_ID"] = ""
+	}
+	if intf, ok := d.GetOk("pc_interface"); ok {
+		nvPairMap["MEMBER_INTERFACES"] = listToString(intf)
+	} else {
+		nvPairMap["MEMBER_INTERFACES"] = ""
+	}
+	if mtu, ok := d.GetOk("mtu"); ok {
+		nvPairMap["MTU"] = mtu.(string)
+	} else {
+		nvPairMap["MTU"] = ""
+	}
+	if desc, ok := d.GetOk("description"); ok {
+		nvPairMap["DESC"] = desc.(string)
+	} else {
+		nvPairMap["DESC"] = ""
+	}
+	if conf, ok := d.GetOk("configuration"); ok {
+		nvPairMap["CONF"] = conf.(string)
+	} else {
+		nvPairMap["CONF"] = ""
+	}
+}
+
+func resourceDCNMInterfaceImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
 	log.Println("[DEBUG] Begining Importer ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	var serialNum1 string
 	var serialNum2 string
@@ -493,6 +806,36 @@ func resourceDCNMInterfaceImporter(d *schema.ResourceData, m interface{}) ([]*sc
 	} else {
 		serialNum1 = serialNum
 	}
+	d.Set("default_policy", defaultEthernetPolicy)
+
+	if intfType == "breakout" {
+		d.Set("serial_number", serialNum)
+		d.SetId(name)
+		if diags := resourceDCNMInterfaceBreakoutRead(ctx, d, m); diags.HasError() {
+			return nil, fmt.Errorf("%s", diags[0].Summary)
+		}
+		if d.Id() == "" {
+			return nil, fmt.Errorf("interface %s is not broken out", name)
+		}
+
+		if swName1, err := getSwitchName(dcnmClient, d.Get("fabric_name").(string), serialNum); err == nil {
+			d.Set("switch_name_1", swName1)
+		}
+		d.Set("name", name)
+
+		// the port settings do not apply to a breakout, their defaults are kept.
+		d.Set("admin_state", true)
+		d.Set("port_fast_flag", true)
+
+		flag, err := checkIntfDeploy(dcnmClient, serialNum, breakoutPort(name), "ethernet")
+		if err != nil {
+			return nil, err
+		}
+		d.Set("deploy", flag)
+
+		log.Println("[DEBUG] End of Importer ", d.Id())
+		return []*schema.ResourceData{d}, nil
+	}
 
 	cont, err := getRemoteInterface(dcnmClient, serialNum1, name)
 	if err != nil {
@@ -534,10 +877,10 @@ func resourceDCNMInterfaceImporter(d *schema.ResourceData, m interface{}) ([]*sc
 	return []*schema.ResourceData{importState}, nil
 }
 
-func resourceDCNMInterfaceCreate(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMInterfaceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Create method ")
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	fabricName := d.Get("fabric_name").(string)
 	policy := d.Get("policy").(string)
@@ -554,10 +897,14 @@ func resourceDCNMInterfaceCreate(d *schema.ResourceData, m interface{}) error {
 	switch1 := d.Get("switch_name_1")
 	switchCont, err := getRemoteSwitchforDS(dcnmClient, fabricName, switch1.(string))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	serial1 := stripQuotes(switchCont.S("serialNumber").String())
 
+	if intfType == "breakout" {
+		return resourceDCNMInterfaceBreakoutCreate(ctx, d, m, serial1)
+	}
+
 	nvPairMap := make(map[string]interface{})
 	nvPairMap["INTF_NAME"] = name
 
@@ -622,11 +969,11 @@ func resourceDCNMInterfaceCreate(d *schema.ResourceData, m interface{}) error {
 		if switch2, ok := d.GetOk("switch_name_2"); ok {
 			switchCont, err := getRemoteSwitchforDS(dcnmClient, fabricName, switch2.(string))
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
 			serial2 = stripQuotes(switchCont.S("serialNumber").String())
 		} else {
-			return fmt.Errorf("switch_name_2 field is required for vpc interface")
+			return diag.Errorf("switch_name_2 field is required for vpc interface")
 		}
 
 		intf.Type = "INTERFACE_VPC"
@@ -814,7 +1161,28 @@ func resourceDCNMInterfaceCreate(d *schema.ResourceData, m interface{}) error {
 		}
 
 	} else if intfType == "ethernet" {
-		return fmt.Errorf("Ethernet interface can only be modified")
+		intf.Type = "INTERFACE_ETHERNET"
+		intfConfig.InterfaceType = "INTERFACE_ETHERNET"
+		intfConfig.SerialNumber = serial1
+		setEthernetNVPairs(d, nvPairMap)
+
+	} else if intfType == "svi" {
+		intf.Type = "INTERFACE_VLAN"
+		intfConfig.InterfaceType = "INTERFACE_VLAN"
+		intfConfig.SerialNumber = serial1
+		setSVINVPairs(d, nvPairMap)
+
+	} else if intfType == "nve" {
+		intf.Type = "INTERFACE_NVE"
+		intfConfig.InterfaceType = "INTERFACE_NVE"
+		intfConfig.SerialNumber = serial1
+		setNVENVPairs(d, nvPairMap)
+
+	} else if intfType == "fex" {
+		intf.Type = "INTERFACE_PORT_CHANNEL"
+		intfConfig.InterfaceType = "INTERFACE_PORT_CHANNEL"
+		intfConfig.SerialNumber = serial1
+		setFEXNVPairs(d, nvPairMap, name)
 
 	}
 
@@ -824,17 +1192,26 @@ func resourceDCNMInterfaceCreate(d *schema.ResourceData, m interface{}) error {
 		nvPairMap["ADMIN_STATE"] = ""
 	}
 
+	mergeInterfaceNVPairs(d, nvPairMap)
+
 	intfModel := models.NewInterface(&intf, &intfConfig, nvPairMap)
 
-	cont, err := dcnmClient.Save("/rest/interface", intfModel)
+	// ethernet ports always exist on the switch, they are taken over by
+	// updating them.
+	var cont *container.Container
+	if intfType == "ethernet" {
+		cont, err = dcnmClient.Update("/rest/interface", intfModel)
+	} else {
+		cont, err = dcnmClient.Save("/rest/interface", intfModel)
+	}
 	if err != nil {
 		if cont != nil {
 			errorMsg, flag := checkIntfErrors(cont)
 			if flag {
-				return fmt.Errorf(errorMsg)
+				return diag.Errorf(errorMsg)
 			}
 		} else {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -854,21 +1231,28 @@ func resourceDCNMInterfaceCreate(d *schema.ResourceData, m interface{}) error {
 			errorMsg, flag := checkIntfErrors(cont)
 			if flag {
 				d.Set("deploy", false)
-				return fmt.Errorf("interface is created but failed to deploy with error : %s", errorMsg)
+				return append(resourceDCNMInterfaceRead(ctx, d, m), deployWarning("interface is created but failed to deploy", fmt.Errorf("%s", errorMsg)))
 			}
 		}
 
+		err = waitForDeployment(ctx, d.Timeout(schema.TimeoutCreate), func() (bool, error) {
+			return checkIntfDeploy(dcnmClient, intfConfig.SerialNumber, intfConfig.InterfaceName, intfType)
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
 		log.Println("[DEBUG] End of Deployment ", d.Id())
 	}
 
 	log.Println("[DEBUG] End of Create method ", d.Id())
-	return resourceDCNMInterfaceRead(d, m)
+	return resourceDCNMInterfaceRead(ctx, d, m)
 }
 
-func resourceDCNMInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMInterfaceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Update method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	fabricName := d.Get("fabric_name").(string)
 	policy := d.Get("policy").(string)
@@ -876,29 +1260,37 @@ func resourceDCNMInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
 	intfType := d.Get("type").(string)
 	serialnum := d.Get("serial_number").(string)
 
+	if intfType == "breakout" {
+		return resourceDCNMInterfaceBreakoutUpdate(ctx, d, m)
+	}
+	// the reset settings are only used on delete.
+	if !d.HasChangesExcept("default_policy", "default_nv_pairs") {
+		return resourceDCNMInterfaceRead(ctx, d, m)
+	}
+
 	if d.HasChange("switch_name_1") {
 		switch1Old, switch1New := d.GetChange("switch_name_1")
 		switchCont, err := getRemoteSwitchforDS(dcnmClient, fabricName, switch1New.(string))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		serial1 := stripQuotes(switchCont.S("serialNumber").String())
 		if intfType == "vpc" && d.HasChange("switch_name_2") {
 			switch2Old, switch2New := d.GetChange("switch_name_2")
 			switchCont, err := getRemoteSwitchforDS(dcnmClient, fabricName, switch2New.(string))
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
 			serial2 := stripQuotes(switchCont.S("serialNumber").String())
 			serial := fmt.Sprintf("%s~%s", serial1, serial2)
 			if serial != serialnum {
 				d.Set("switch_name_1", switch1Old)
 				d.Set("switch_name_2", switch2Old)
-				return fmt.Errorf("switch names should not be updated")
+				return diag.Errorf("switch names should not be updated")
 			}
 		} else if serial1 != serialnum {
 			d.Set("switch_name_1", switch1Old)
-			return fmt.Errorf("switch names should not be updated")
+			return diag.Errorf("switch names should not be updated")
 		}
 	}
 
@@ -1147,65 +1539,19 @@ func resourceDCNMInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
 
 	} else if intfType == "ethernet" {
 		intfConfig.SerialNumber = serialnum
+		setEthernetNVPairs(d, nvPairMap)
 
-		if bpduF, ok := d.GetOk("bpdu_gaurd_flag"); ok {
-			nvPairMap["BPDUGUARD_ENABLED"] = bpduF.(string)
-		} else {
-			nvPairMap["BPDUGUARD_ENABLED"] = ""
-		}
-		if pff, ok := d.GetOk("port_fast_flag"); ok {
-			nvPairMap["PORTTYPE_FAST_ENABLED"] = pff.(bool)
-		}
-		if mtu, ok := d.GetOk("mtu"); ok {
-			nvPairMap["MTU"] = mtu.(string)
-		} else {
-			nvPairMap["MTU"] = ""
-		}
-		if speed, ok := d.GetOk("ethernet_speed"); ok {
-			nvPairMap["SPEED"] = speed.(string)
-		} else {
-			nvPairMap["SPEED"] = ""
-		}
-		if vlans, ok := d.GetOk("allowed_vlans"); ok {
-			nvPairMap["ALLOWED_VLANS"] = vlans.(string)
-		} else {
-			nvPairMap["ALLOWED_VLANS"] = ""
-		}
-		if ip, ok := d.GetOk("ipv4"); ok {
-			nvPairMap["IP"] = ip.(string)
-		} else {
-			nvPairMap["IP"] = ""
-		}
-		if ipv4Pre, ok := d.GetOk("ipv4_prefix"); ok {
-			nvPairMap["PREFIX"] = ipv4Pre.(string)
-		} else {
-			nvPairMap["PREFIX"] = ""
-		}
-		if ipv6, ok := d.GetOk("ipv6"); ok {
-			nvPairMap["IPv6"] = ipv6.(string)
-		} else {
-			nvPairMap["IPv6"] = ""
-		}
-		if ipv6Pre, ok := d.GetOk("ipv6_prefix"); ok {
-			nvPairMap["IPv6_PREFIX"] = ipv6Pre.(string)
-		} else {
-			nvPairMap["IPv6_PREFIX"] = ""
-		}
-		if accVlans, ok := d.GetOk("access_vlans"); ok {
-			nvPairMap["ACCESS_VLAN"] = accVlans.(string)
-		} else {
-			nvPairMap["ACCESS_VLAN"] = ""
-		}
-		if desc, ok := d.GetOk("description"); ok {
-			nvPairMap["DESC"] = desc.(string)
-		} else {
-			nvPairMap["DESC"] = ""
-		}
-		if conf, ok := d.GetOk("configuration"); ok {
-			nvPairMap["CONF"] = conf.(string)
-		} else {
-			nvPairMap["CONF"] = ""
-		}
+	} else if intfType == "svi" {
+		intfConfig.SerialNumber = serialnum
+		setSVINVPairs(d, nvPairMap)
+
+	} else if intfType == "nve" {
+		intfConfig.SerialNumber = serialnum
+		setNVENVPairs(d, nvPairMap)
+
+	} else if intfType == "fex" {
+		intfConfig.SerialNumber = serialnum
+		setFEXNVPairs(d, nvPairMap, name)
 
 	}
 
@@ -1215,6 +1561,8 @@ func resourceDCNMInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
 		nvPairMap["ADMIN_STATE"] = ""
 	}
 
+	mergeInterfaceNVPairs(d, nvPairMap)
+
 	intfModel := models.NewInterface(&intf, &intfConfig, nvPairMap)
 
 	cont, err := dcnmClient.Update("/rest/interface", intfModel)
@@ -1222,10 +1570,10 @@ func resourceDCNMInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
 		if cont != nil {
 			errorMsg, flag := checkIntfErrors(cont)
 			if flag {
-				return fmt.Errorf(errorMsg)
+				return diag.Errorf(errorMsg)
 			}
 		} else {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -1233,10 +1581,6 @@ func resourceDCNMInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
 	d.Set("type", intfType)
 	d.SetId(intfConfig.InterfaceName)
 
-	if d.HasChange("deploy") && d.Get("deploy").(bool) == false {
-		return fmt.Errorf("Deployed interface can not be undeployed")
-	}
-
 	//Deployment of interface
 	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
 		log.Println("[DEBUG] Begining Deployment ", d.Id())
@@ -1249,10 +1593,17 @@ func resourceDCNMInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
 			errorMsg, flag := checkIntfErrors(cont)
 			if flag {
 				d.Set("deploy", false)
-				return fmt.Errorf("interface is created but failed to deploy with error : %s", errorMsg)
+				return append(resourceDCNMInterfaceRead(ctx, d, m), deployWarning("interface is updated but failed to deploy", fmt.Errorf("%s", errorMsg)))
 			}
 		}
 
+		err = waitForDeployment(ctx, d.Timeout(schema.TimeoutUpdate), func() (bool, error) {
+			return checkIntfDeploy(dcnmClient, intfConfig.SerialNumber, intfConfig.InterfaceName, intfType)
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
 		log.Println("[DEBUG] End of Deployment ", d.Id())
 	}
 
@@ -1260,15 +1611,18 @@ func resourceDCNMInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceDCNMInterfaceRead(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMInterfaceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Read method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	var serialNum1 string
 	dn := d.Id()
 	intfType := d.Get("type").(string)
 	serialNum := d.Get("serial_number").(string)
+	if intfType == "breakout" {
+		return resourceDCNMInterfaceBreakoutRead(ctx, d, m)
+	}
 	if intfType == "vpc" {
 		serialNum1 = (strings.Split(d.Get("serial_number").(string), "~"))[0]
 	} else {
@@ -1277,40 +1631,58 @@ func resourceDCNMInterfaceRead(d *schema.ResourceData, m interface{}) error {
 
 	cont, err := getRemoteInterface(dcnmClient, serialNum1, dn)
 	if err != nil {
+		if isNotFound(err) {
+			log.Printf("[WARN] Interface %s not found, removing from state", dn)
+			d.SetId("")
+			return nil
+		}
 		if cont != nil {
 			errorMsg, flag := checkIntfErrors(cont)
 			if flag {
-				return fmt.Errorf(errorMsg)
+				return diag.Errorf(errorMsg)
 			}
 		} else {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
+	if intfs, ok := cont.Data().([]interface{}); ok && len(intfs) == 0 {
+		log.Printf("[WARN] Interface %s not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
 	setInterfaceAttributes(d, cont.Index(0), intfType)
 	d.SetId(dn)
 
-	flag, err := checkIntfDeploy(dcnmClient, serialNum, d.Get("name").(string), intfType)
-	if err != nil {
-		return err
+	// with deploy=false the interface is deployed by dcnm_deployment, its
+	// deployment is not tracked here.
+	if d.Get("deploy").(bool) {
+		flag, err := checkIntfDeploy(dcnmClient, serialNum, d.Get("name").(string), intfType)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set("deploy", flag)
 	}
-	d.Set("deploy", flag)
 
 	log.Println("[DEBUG] End of Read method ", d.Id())
 	return nil
 }
 
-func resourceDCNMInterfaceDelete(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMInterfaceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Delete method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	dn := d.Id()
 	serialNum := d.Get("serial_number").(string)
 	intfType := d.Get("type").(string)
 
 	if intfType == "ethernet" {
-		return fmt.Errorf("Interface of type Ethernet can not be deleted")
+		return resourceDCNMInterfaceEthernetReset(ctx, d, m)
+	}
+	if intfType == "breakout" {
+		return resourceDCNMInterfaceBreakoutDelete(ctx, d, m)
 	}
 
 	intfDel := models.InterfaceDelete{}
@@ -1321,10 +1693,324 @@ func resourceDCNMInterfaceDelete(d *schema.ResourceData, m interface{}) error {
 		if cont != nil {
 			errorMsg, flag := checkIntfErrors(cont)
 			if flag {
-				return fmt.Errorf(errorMsg)
+				return diag.Errorf(errorMsg)
 			}
 		} else {
-			return err
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+
+	log.Println("[DEBUG] End of Delete method ")
+	return nil
+}
+
+// resourceDCNMInterfaceEthernetReset puts the ethernet port back on the default
+// policy, with no description and admin up, as it can not be deleted.
+func resourceDCNMInterfaceEthernetReset(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	dn := d.Id()
+	serialNum := d.Get("serial_number").(string)
+
+	intf := models.Interface{}
+	intf.Policy = d.Get("default_policy").(string)
+	intf.Type = "INTERFACE_ETHERNET"
+
+	intfConfig := models.InterfaceConfig{}
+	intfConfig.Fabric = d.Get("fabric_name").(string)
+	intfConfig.InterfaceName = dn
+	intfConfig.InterfaceType = "INTERFACE_ETHERNET"
+	intfConfig.SerialNumber = serialNum
+
+	nvPairMap := make(map[string]interface{})
+	nvPairMap["INTF_NAME"] = dn
+	nvPairMap["DESC"] = ""
+	nvPairMap["CONF"] = ""
+	nvPairMap["ADMIN_STATE"] = true
+	if defaults, ok := d.GetOk("default_nv_pairs"); ok {
+		for key, val := range defaults.(map[string]interface{}) {
+			nvPairMap[key] = val.(string)
+		}
+	}
+
+	intfModel := models.NewInterface(&intf, &intfConfig, nvPairMap)
+	cont, err := dcnmClient.Update("/rest/interface", intfModel)
+	if err != nil {
+		if cont != nil {
+			if _, ok := cont.Data().([]interface{}); ok {
+				if errorMsg, flag := checkIntfErrors(cont); flag {
+					return diag.Errorf(errorMsg)
+				}
+			}
+		}
+		return diag.FromErr(err)
+	}
+
+	if d.Get("deploy").(bool) {
+		log.Println("[DEBUG] Begining Deployment ", dn)
+
+		intfDeploy := models.InterfaceDelete{}
+		intfDeploy.SerialNumber = serialNum
+		intfDeploy.Name = dn
+		cont, err = dcnmClient.SaveForAttachment("/rest/interface/deploy", &intfDeploy)
+		if err != nil {
+			if cont != nil {
+				if _, ok := cont.Data().([]interface{}); ok {
+					if errorMsg, flag := checkIntfErrors(cont); flag {
+						return diag.Errorf("interface is reset but failed to deploy: %s", errorMsg)
+					}
+				}
+			}
+			return diag.Errorf("interface is reset but failed to deploy: %s", err)
+		}
+
+		err = waitForDeployment(ctx, d.Timeout(schema.TimeoutDelete), func() (bool, error) {
+			return checkIntfDeploy(dcnmClient, serialNum, dn, "ethernet")
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		log.Println("[DEBUG] End of Deployment ", dn)
+	}
+
+	d.SetId("")
+
+	log.Println("[DEBUG] End of Delete method ")
+	return nil
+}
+
+// interfaceBreakout breaks a port out into several ports with the given map,
+// or joins them back without a map.
+type interfaceBreakout struct {
+	SerialNumber string
+	Name         string
+	Map          string
+}
+
+func (breakout *interfaceBreakout) ToMap() (map[string]interface{}, error) {
+	breakoutMap := map[string]interface{}{
+		"serialNumber": breakout.SerialNumber,
+		"ifName":       breakout.Name,
+	}
+	if breakout.Map != "" {
+		breakoutMap["map"] = breakout.Map
+	}
+	return breakoutMap, nil
+}
+
+// getBreakoutMap returns the map of the breakout of the port, from the number
+// of ports it is broken out into and their speed. It is empty when the speed
+// of the ports is not known.
+func getBreakoutMap(client *Client, serialNum, name string) (string, error) {
+	durl := fmt.Sprintf("/rest/interface?serialNumber=%s", serialNum)
+	cont, err := client.GetviaURL(durl)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := strings.ToLower(name + "/")
+	count := 0
+	speed := ""
+	policies, ok := cont.Data().([]interface{})
+	if !ok {
+		return "", fmt.Errorf("unexpected interface list of switch %s : %s", serialNum, cont.String())
+	}
+
+	for i := 0; i < len(policies); i++ {
+		intfCont := cont.Index(i)
+		interfaces, _ := intfCont.S("interfaces").Data().([]interface{})
+		for j := 0; j < len(interfaces); j++ {
+			intf := intfCont.S("interfaces").Index(j)
+			ifName := strings.ToLower(stripQuotes(intf.S("ifName").String()))
+			if !strings.HasPrefix(ifName, prefix) || strings.Contains(strings.TrimPrefix(ifName, prefix), "/") {
+				continue
+			}
+			count++
+			if speed == "" {
+				speed = stripQuotes(intf.S("nvPairs", "SPEED").String())
+			}
+		}
+	}
+
+	var gbps int
+	if _, err := fmt.Sscanf(speed, "%dGb", &gbps); err != nil || count == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("%dg-%dx", gbps, count), nil
+}
+
+// breakoutPort returns the first of the ports a port is broken out into,
+// which tells whether the breakout exists and is deployed.
+func breakoutPort(name string) string {
+	return fmt.Sprintf("%s/1", name)
+}
+
+// deployBreakout deploys the breakout, which is part of the switch
+// configuration rather than of an interface policy.
+func deployBreakout(ctx context.Context, client *Client, fabricName, serialNum, port string, timeout time.Duration) error {
+	log.Println("[DEBUG] Begining Deployment ", port)
+
+	durl := fmt.Sprintf("/rest/control/fabrics/%s/config-deploy/%s", fabricName, serialNum)
+	if _, err := client.SaveAndDeploy(durl); err != nil {
+		return err
+	}
+
+	err := waitForDeployment(ctx, timeout, func() (bool, error) {
+		return checkIntfDeploy(client, serialNum, port, "ethernet")
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Println("[DEBUG] End of Deployment ", port)
+	return nil
+}
+
+func resourceDCNMInterfaceBreakoutCreate(ctx context.Context, d *schema.ResourceData, m interface{}, serialNum string) diag.Diagnostics {
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	name := d.Get("name").(string)
+	breakoutMap := d.Get("breakout_map").(string)
+	if breakoutMap == "" {
+		return diag.Errorf("breakout_map is required for breakout interfaces")
+	}
+
+	breakout := interfaceBreakout{
+		SerialNumber: serialNum,
+		Name:         name,
+		Map:          breakoutMap,
+	}
+	if _, err := dcnmClient.SaveForAttachment("/rest/interface/breakout", &breakout); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set("serial_number", serialNum)
+	d.Set("type", "breakout")
+	d.SetId(name)
+
+	if d.Get("deploy").(bool) {
+		err := deployBreakout(ctx, dcnmClient, d.Get("fabric_name").(string), serialNum, breakoutPort(name), d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			d.Set("deploy", false)
+			return append(resourceDCNMInterfaceBreakoutRead(ctx, d, m), deployWarning("interface is broken out but failed to deploy", err))
+		}
+	}
+
+	log.Println("[DEBUG] End of Create method ", d.Id())
+	return resourceDCNMInterfaceBreakoutRead(ctx, d, m)
+}
+
+func resourceDCNMInterfaceBreakoutUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	name := d.Id()
+	serialNum := d.Get("serial_number").(string)
+
+	// a port is broken out with another map by joining it back first.
+	if d.HasChange("breakout_map") {
+		breakout := interfaceBreakout{
+			SerialNumber: serialNum,
+			Name:         name,
+		}
+		if _, err := dcnmClient.DeleteWithPayload("/rest/interface/breakout", &breakout); err != nil {
+			return diag.FromErr(err)
+		}
+
+		breakout.Map = d.Get("breakout_map").(string)
+		if _, err := dcnmClient.SaveForAttachment("/rest/interface/breakout", &breakout); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// the deployment pushes the whole switch configuration, so it is only
+	// done when the breakout changed or was not deployed yet.
+	if d.Get("deploy").(bool) && d.HasChanges("breakout_map", "deploy") {
+		err := deployBreakout(ctx, dcnmClient, d.Get("fabric_name").(string), serialNum, breakoutPort(name), d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			d.Set("deploy", false)
+			return append(resourceDCNMInterfaceBreakoutRead(ctx, d, m), deployWarning("interface is broken out but failed to deploy", err))
+		}
+	}
+
+	log.Println("[DEBUG] End of Update method ", d.Id())
+	return resourceDCNMInterfaceBreakoutRead(ctx, d, m)
+}
+
+// resourceDCNMInterfaceBreakoutRead reads a breakout from the ports it
+// created, it no longer exists once the port is joined back.
+func resourceDCNMInterfaceBreakoutRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	dn := d.Id()
+	serialNum := d.Get("serial_number").(string)
+	port := breakoutPort(dn)
+
+	cont, err := getRemoteInterface(dcnmClient, serialNum, port)
+	if err != nil && !isNotFound(err) {
+		return diag.FromErr(err)
+	}
+	if err == nil {
+		if _, ok := cont.Data().([]interface{}); !ok {
+			return diag.Errorf("unexpected interface %s : %s", port, cont.String())
+		}
+	}
+	if err != nil || len(cont.Data().([]interface{})) == 0 {
+		log.Printf("[WARN] Breakout of interface %s not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	interfaces := cont.Index(0).S("interfaces").Index(0)
+	d.Set("fabric_name", stripQuotes(interfaces.S("nvPairs", "FABRIC_NAME").String()))
+	d.Set("type", "breakout")
+	d.Set("pc_interface", make([]interface{}, 0, 1))
+	d.Set("vpc_peer1_interface", make([]interface{}, 0, 1))
+	d.Set("vpc_peer2_interface", make([]interface{}, 0, 1))
+	d.Set("nv_pairs", make(map[string]interface{}))
+
+	breakoutMap, err := getBreakoutMap(dcnmClient, serialNum, dn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if breakoutMap != "" {
+		d.Set("breakout_map", breakoutMap)
+	}
+
+	if d.Get("deploy").(bool) {
+		flag, err := checkIntfDeploy(dcnmClient, serialNum, port, "ethernet")
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set("deploy", flag)
+	}
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
+
+func resourceDCNMInterfaceBreakoutDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	name := d.Id()
+	serialNum := d.Get("serial_number").(string)
+
+	breakout := interfaceBreakout{
+		SerialNumber: serialNum,
+		Name:         name,
+	}
+	if _, err := dcnmClient.DeleteWithPayload("/rest/interface/breakout", &breakout); err != nil {
+		if !isNotFound(err) {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.Get("deploy").(bool) {
+		err := deployBreakout(ctx, dcnmClient, d.Get("fabric_name").(string), serialNum, name, d.Timeout(schema.TimeoutDelete))
+		if err != nil {
+			return diag.FromErr(err)
 		}
 	}
 
@@ -1347,7 +2033,7 @@ func checkIntfErrors(cont *container.Container) (string, bool) {
 	return errMsg, flag
 }
 
-func checkIntfDeploy(client *client.Client, serialnum, name, intftype string) (bool, error) {
+func checkIntfDeploy(client *Client, serialnum, name, intftype string) (bool, error) {
 	flag := false
 	intfStr := fmt.Sprintf("%s~%s", serialnum, name)
 
@@ -1372,7 +2058,7 @@ func checkIntfDeploy(client *client.Client, serialnum, name, intftype string) (b
 
 	totalIntf := len(cont.Data().([]interface{}))
 	for i := 0; i < totalIntf; i++ {
-		if stripQuotes(cont.Index(i).S("entityId").String()) == intfStr {
+		if strings.EqualFold(stripQuotes(cont.Index(i).S("entityId").String()), intfStr) {
 			if stripQuotes(cont.Index(i).S("complianceStatus").String()) == "In-Sync" {
 				return true, nil
 			}
@@ -1382,7 +2068,7 @@ func checkIntfDeploy(client *client.Client, serialnum, name, intftype string) (b
 	return flag, nil
 }
 
-func getSwitchName(client *client.Client, fabric, serialNum string) (string, error) {
+func getSwitchName(client *Client, fabric, serialNum string) (string, error) {
 	durl := fmt.Sprintf("/rest/control/fabrics/%s/inventory", fabric)
 
 	cont, err := client.GetviaURL(durl)
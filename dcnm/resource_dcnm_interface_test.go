@@ -1,10 +1,12 @@
 package dcnm
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/models"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -92,7 +94,7 @@ func testAccCheckDCNMInterfaceExists(name string, inv *models.Interface) resourc
 			return fmt.Errorf("No Interface dn was set")
 		}
 
-		dcnmClient := (*providerIntf).Meta().(*client.Client)
+		dcnmClient := (*providerIntf).Meta().(*Client)
 
 		cont, err := dcnmClient.GetviaURL(fmt.Sprintf("/rest/interface?ifName=%s", rs.Primary.ID))
 		if err != nil {
@@ -124,7 +126,7 @@ func testAccCheckDCNMInterfaceExists(name string, inv *models.Interface) resourc
 }
 
 func testAccCheckDCNMInterfaceDestroy(s *terraform.State) error {
-	dcnmClient := (*providerIntf).Meta().(*client.Client)
+	dcnmClient := (*providerIntf).Meta().(*Client)
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type == "dcnm_interface" {
@@ -173,3 +175,422 @@ func testAccCheckDCNMInterfaceAttributes(desc string, intf *models.Interface) re
 		return nil
 	}
 }
+
+func TestDCNMInterface_MockLoopback(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterface()
+
+	raw := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "loopback100",
+		"type":          "loopback",
+		"policy":        "int_loopback_11_1",
+		"switch_name_1": "leaf1",
+		"ipv4":          "10.10.10.1",
+		"description":   "first",
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "id", "loopback100")
+	testMockCheckAttr(t, state, "fabric_name", "fab1")
+	testMockCheckAttr(t, state, "ipv4", "10.10.10.1")
+	testMockCheckAttr(t, state, "deploy", "true")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["description"] = "second"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "description", "second")
+
+	testMockDestroy(t, r, state, dcnmClient)
+	cont, err := getRemoteInterface(dcnmClient, state.Attributes["serial_number"], "loopback100")
+	if err != nil || len(cont.Data().([]interface{})) != 0 {
+		t.Fatalf("Interface still exists")
+	}
+}
+
+func TestDCNMInterface_MockEthernet(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterface()
+
+	serial := testMockSerial(t, dcnmClient, "leaf2")
+
+	state := testMockImport(t, r, fmt.Sprintf("ethernet:%s:Ethernet1/3", serial), dcnmClient)
+	testMockCheckAttr(t, state, "switch_name_1", "leaf2")
+	testMockCheckAttr(t, state, "policy", "int_trunk_host_11_1")
+	testMockCheckAttr(t, state, "deploy", "true")
+
+	raw := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "Ethernet1/3",
+		"type":          "ethernet",
+		"policy":        "int_trunk_host_11_1",
+		"switch_name_1": "leaf2",
+		"mtu":           "jumbo",
+		"description":   "uplink",
+	}
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "description", "uplink")
+	testMockCheckAttr(t, state, "default_policy", "int_trunk_host_11_1")
+
+	// failures to reset the port are returned, whatever their body.
+	testMockServer.failNext(1, http.StatusInternalServerError, "reset failed")
+	_, diags := r.Apply(context.Background(), state, &terraform.InstanceDiff{Destroy: true}, dcnmClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "reset failed") {
+		t.Fatalf("expected reset error, got : %v", diags)
+	}
+
+	testMockDestroy(t, r, state, dcnmClient)
+	key := mockIntfKey(serial, "Ethernet1/3")
+	testMockServer.outOfBand(func() {
+		intf := testMockServer.interfaces[key]
+		if intf == nil || intf["policy"] != "int_trunk_host_11_1" {
+			t.Fatalf("expected Ethernet1/3 reset to int_trunk_host_11_1, got : %v", intf)
+		}
+		nvPairs := intf["nvPairs"].(map[string]interface{})
+		if nvPairs["DESC"] != "" || nvPairs["ADMIN_STATE"] != "true" {
+			t.Fatalf("expected no description and admin up, got : %v", nvPairs)
+		}
+		if !testMockServer.intfDeployed[key] {
+			t.Fatalf("expected Ethernet1/3 to be redeployed")
+		}
+	})
+}
+
+func TestDCNMInterface_MockDeletedOutOfBand(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterface()
+
+	raw := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "loopback101",
+		"type":          "loopback",
+		"policy":        "int_loopback_11_1",
+		"switch_name_1": "leaf1",
+		"ipv4":          "10.10.10.2",
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockServer.outOfBand(func() {
+		key := mockIntfKey(state.Attributes["serial_number"], "loopback101")
+		delete(testMockServer.interfaces, key)
+		delete(testMockServer.intfDeployed, key)
+	})
+	testMockRefreshGone(t, r, state, dcnmClient)
+}
+
+func TestDCNMInterface_MockListDataSource(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	serial := testMockSerial(t, dcnmClient, "leaf1")
+
+	data := testMockReadData(t, datasourceDCNMInterfaces(), map[string]interface{}{
+		"serial_number": serial,
+		"name_regex":    "^Ethernet1/[12]$",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "id", serial)
+	testMockCheckAttr(t, data, "interfaces.#", "2")
+	testMockCheckAttr(t, data, "interfaces.0.name", "Ethernet1/1")
+	testMockCheckAttr(t, data, "interfaces.0.type", "ethernet")
+	testMockCheckAttr(t, data, "interfaces.0.fabric_name", "fab1")
+	testMockCheckAttr(t, data, "interfaces.0.admin_status", "up")
+	testMockCheckAttr(t, data, "interfaces.1.name", "Ethernet1/2")
+
+	data = testMockReadData(t, datasourceDCNMInterfaces(), map[string]interface{}{
+		"serial_number": serial,
+		"type":          "loopback",
+		"name_regex":    "^loopback999$",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "interfaces.#", "0")
+}
+
+func TestDCNMInterface_MockNVPairs(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterface()
+
+	raw := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "loopback102",
+		"type":          "loopback",
+		"policy":        "int_loopback_11_1",
+		"switch_name_1": "leaf1",
+		"ipv4":          "10.10.10.3",
+		"nv_pairs": map[string]interface{}{
+			"PTP":            "true",
+			"ENABLE_NETFLOW": "false",
+		},
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "nv_pairs.%", "2")
+	testMockCheckAttr(t, state, "nv_pairs.PTP", "true")
+	testMockCheckAttr(t, state, "ipv4", "10.10.10.3")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["nv_pairs"] = map[string]interface{}{
+		"PTP":            "false",
+		"ENABLE_NETFLOW": "false",
+	}
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "nv_pairs.PTP", "false")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	key := mockIntfKey(state.Attributes["serial_number"], "loopback102")
+	testMockServer.outOfBand(func() {
+		testMockServer.interfaces[key]["nvPairs"].(map[string]interface{})["PTP"] = "true"
+	})
+	testMockPlanChanged(t, r, state, raw, dcnmClient)
+
+	data := testMockReadData(t, datasourceDCNMInterface(), map[string]interface{}{
+		"serial_number": state.Attributes["serial_number"],
+		"name":          "loopback102",
+		"type":          "loopback",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "nv_pairs.PTP", "true")
+	testMockCheckAttr(t, data, "nv_pairs.IP", "10.10.10.3")
+
+	// a key removed from nv_pairs is reset to the policy default.
+	raw["nv_pairs"] = map[string]interface{}{
+		"PTP": "false",
+	}
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "nv_pairs.%", "1")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	var netflow interface{}
+	testMockServer.outOfBand(func() {
+		netflow = testMockServer.interfaces[key]["nvPairs"].(map[string]interface{})["ENABLE_NETFLOW"]
+	})
+	if netflow != "" {
+		t.Fatalf("expected removed key to be reset, got : %v", netflow)
+	}
+
+	testMockDestroy(t, r, state, dcnmClient)
+}
+
+func TestDCNMInterface_MockSVI(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterface()
+
+	raw := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "vlan100",
+		"type":          "svi",
+		"policy":        "int_vlan",
+		"switch_name_1": "leaf1",
+		"vrf":           "mock_vrf",
+		"ipv4":          "10.100.0.1",
+		"ipv4_prefix":   "24",
+		"mtu":           "9216",
+		"description":   "first",
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "id", "vlan100")
+	testMockCheckAttr(t, state, "vrf", "mock_vrf")
+	testMockCheckAttr(t, state, "ipv4_prefix", "24")
+	testMockCheckAttr(t, state, "deploy", "true")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["description"] = "second"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "description", "second")
+
+	data := testMockReadData(t, datasourceDCNMInterfaces(), map[string]interface{}{
+		"serial_number": state.Attributes["serial_number"],
+		"type":          "svi",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "interfaces.#", "1")
+	testMockCheckAttr(t, data, "interfaces.0.name", "vlan100")
+
+	imported := testMockImport(t, r, fmt.Sprintf("svi:%s:vlan100", state.Attributes["serial_number"]), dcnmClient)
+	testMockCheckAttr(t, imported, "ipv4", "10.100.0.1")
+
+	testMockDestroy(t, r, state, dcnmClient)
+	cont, err := getRemoteInterface(dcnmClient, state.Attributes["serial_number"], "vlan100")
+	if err != nil || len(cont.Data().([]interface{})) != 0 {
+		t.Fatalf("Interface still exists")
+	}
+}
+
+func TestDCNMInterface_MockNVE(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterface()
+
+	raw := map[string]interface{}{
+		"fabric_name":          "fab1",
+		"name":                 "nve1",
+		"type":                 "nve",
+		"policy":               "nve_interface",
+		"switch_name_1":        "leaf2",
+		"nve_source_interface": "loopback1",
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "nve_source_interface", "loopback1")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["nve_source_interface"] = "loopback2"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "nve_source_interface", "loopback2")
+
+	testMockDestroy(t, r, state, dcnmClient)
+}
+
+func TestDCNMInterface_MockFEX(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterface()
+
+	raw := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "port-channel101",
+		"type":          "fex",
+		"policy":        "int_port_channel_fex_11_1",
+		"switch_name_1": "leaf2",
+		"fex_id":        101,
+		"pc_interface":  []interface{}{"Ethernet1/4"},
+		"description":   "rack 1",
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "fex_id", "101")
+	testMockCheckAttr(t, state, "pc_interface.0", "Ethernet1/4")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	cont, err := getRemoteInterface(dcnmClient, state.Attributes["serial_number"], "port-channel101")
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if intfType := stripQuotes(cont.Index(0).S("interfaces").Index(0).S("interfaceType").String()); intfType != "INTERFACE_PORT_CHANNEL" {
+		t.Fatalf("expected FEX port-channel, got : %s", intfType)
+	}
+
+	testMockDestroy(t, r, state, dcnmClient)
+}
+
+func TestDCNMInterface_MockRoutedEthernet(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterface()
+
+	raw := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "Ethernet1/2",
+		"type":          "ethernet",
+		"policy":        "int_routed_host",
+		"switch_name_1": "leaf2",
+		"vrf":           "mock_vrf",
+		"ipv4":          "10.20.0.1",
+		"ipv4_prefix":   "30",
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "policy", "int_routed_host")
+	testMockCheckAttr(t, state, "vrf", "mock_vrf")
+	testMockCheckAttr(t, state, "deploy", "true")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["default_policy"] = "int_access_host_11_1"
+	raw["default_nv_pairs"] = map[string]interface{}{
+		"ACCESS_VLAN": "10",
+	}
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	testMockDestroy(t, r, state, dcnmClient)
+	key := mockIntfKey(state.Attributes["serial_number"], "Ethernet1/2")
+	testMockServer.outOfBand(func() {
+		intf := testMockServer.interfaces[key]
+		if intf == nil || intf["policy"] != "int_access_host_11_1" {
+			t.Fatalf("expected Ethernet1/2 reset to int_access_host_11_1, got : %v", intf)
+		}
+		if vlan := intf["nvPairs"].(map[string]interface{})["ACCESS_VLAN"]; vlan != "10" {
+			t.Fatalf("expected ACCESS_VLAN = 10, got : %v", vlan)
+		}
+	})
+}
+
+func TestDCNMInterface_MockBreakout(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterface()
+
+	raw := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "Ethernet1/4",
+		"type":          "breakout",
+		"switch_name_1": "leaf1",
+		"breakout_map":  "25g-4x",
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "id", "Ethernet1/4")
+	testMockCheckAttr(t, state, "deploy", "true")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	serial := state.Attributes["serial_number"]
+	cont, err := getRemoteInterface(dcnmClient, serial, "Ethernet1/4/4")
+	if err != nil || len(cont.Data().([]interface{})) != 1 {
+		t.Fatalf("expected Ethernet1/4/4 after the breakout, err : %v", err)
+	}
+
+	// the switch is only deployed again when the breakout changed.
+	deploys := testMockServer.callCount("POST", fmt.Sprintf("/rest/control/fabrics/fab1/config-deploy/%s", serial))
+	raw["description"] = "breakout"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	if got := testMockServer.callCount("POST", fmt.Sprintf("/rest/control/fabrics/fab1/config-deploy/%s", serial)) - deploys; got != 0 {
+		t.Fatalf("expected no switch deployment, got %d", got)
+	}
+
+	// a breakout changed on DCNM is reported.
+	testMockServer.outOfBand(func() {
+		delete(testMockServer.interfaces, mockIntfKey(serial, "Ethernet1/4/4"))
+	})
+	state = testMockRefresh(t, r, state, dcnmClient)
+	testMockCheckAttr(t, state, "breakout_map", "25g-3x")
+	testMockPlanChanged(t, r, state, raw, dcnmClient)
+
+	raw["breakout_map"] = "50g-2x"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	if got := testMockServer.callCount("POST", fmt.Sprintf("/rest/control/fabrics/fab1/config-deploy/%s", serial)) - deploys; got != 1 {
+		t.Fatalf("expected one switch deployment, got %d", got)
+	}
+	testMockCheckAttr(t, state, "breakout_map", "50g-2x")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	cont, err = getRemoteInterface(dcnmClient, serial, "Ethernet1/4/4")
+	if err != nil || len(cont.Data().([]interface{})) != 0 {
+		t.Fatalf("expected Ethernet1/4/4 to be gone with 50g-2x, err : %v", err)
+	}
+
+	imported := testMockImport(t, r, fmt.Sprintf("breakout:%s:Ethernet1/4", serial), dcnmClient)
+	testMockCheckAttr(t, imported, "switch_name_1", "leaf1")
+	testMockCheckAttr(t, imported, "breakout_map", "50g-2x")
+	delete(raw, "description")
+	testMockPlanEmpty(t, r, imported, raw, dcnmClient)
+
+	testMockDestroy(t, r, state, dcnmClient)
+	cont, err = getRemoteInterface(dcnmClient, serial, "Ethernet1/4")
+	if err != nil || len(cont.Data().([]interface{})) != 1 {
+		t.Fatalf("expected Ethernet1/4 back after the breakout is deleted, err : %v", err)
+	}
+	testMockRefreshGone(t, r, state, dcnmClient)
+}
+
+func TestDCNMInterface_PolicyRequired(t *testing.T) {
+	r := resourceDCNMInterface()
+
+	raw := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"name":          "loopback120",
+		"type":          "loopback",
+		"switch_name_1": "leaf1",
+	}
+	_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), nil)
+	if err == nil || !strings.Contains(err.Error(), "policy is required for loopback interfaces") {
+		t.Fatalf("expected policy error at plan time, got : %v", err)
+	}
+
+	raw["policy"] = "int_loopback_11_1"
+	if _, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), nil); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+}
@@ -0,0 +1,661 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/ciscoecosystem/dcnm-go-client/models"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceDCNMInterfaces() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDCNMInterfacesCreate,
+		UpdateContext: resourceDCNMInterfacesUpdate,
+		ReadContext:   resourceDCNMInterfacesRead,
+		DeleteContext: resourceDCNMInterfacesDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"fabric_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			// ports are hashed on their configuration, so adding or removing
+			// one leaves the others alone.
+			"interface": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"switch_name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+								return strings.EqualFold(old, new)
+							},
+						},
+
+						"policy": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"description": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"admin_state": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+
+						"mtu": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"allowed_vlans": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"access_vlans": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"configuration": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"nv_pairs": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+
+						"serial_number": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"compliance_status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			// ports are reset to these when they are removed or the resource
+			// is deleted.
+			"default_policy": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  defaultEthernetPolicy,
+			},
+
+			"default_nv_pairs": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"deploy": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+	}
+}
+
+// interfacePort is the configuration of one ethernet port of dcnm_interfaces.
+type interfacePort struct {
+	serialNumber string
+	name         string
+	policy       string
+	nvPairs      map[string]interface{}
+}
+
+// interfacePortKey returns the switch name and the port name, which DCNM
+// matches case insensitively.
+func interfacePortKey(intf map[string]interface{}) string {
+	return fmt.Sprintf("%s~%s", intf["switch_name"], strings.ToLower(intf["name"].(string)))
+}
+
+// getSwitchSerials returns the serial numbers of the switches of the fabric
+// by name, with a single request for all of the ports.
+func getSwitchSerials(client *Client, fabric string) (map[string]string, error) {
+	durl := fmt.Sprintf("/rest/control/fabrics/%s/inventory", fabric)
+
+	cont, err := client.GetviaURL(durl)
+	if err != nil {
+		return nil, err
+	}
+
+	switches, ok := cont.Data().([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected inventory of fabric %s : %s", fabric, cont.String())
+	}
+
+	serials := make(map[string]string)
+	for i := 0; i < len(switches); i++ {
+		switchCont := cont.Index(i)
+		serials[stripQuotes(switchCont.S("logicalName").String())] = stripQuotes(switchCont.S("serialNumber").String())
+	}
+	return serials, nil
+}
+
+func newInterfacePort(intf map[string]interface{}, serials map[string]string, fabric string) (*interfacePort, error) {
+	switchName := intf["switch_name"].(string)
+	serialNum, ok := serials[switchName]
+	if !ok {
+		return nil, fmt.Errorf("switch %s not found in fabric %s", switchName, fabric)
+	}
+
+	name := intf["name"].(string)
+	nvPairMap := make(map[string]interface{})
+	nvPairMap["INTF_NAME"] = name
+	nvPairMap["DESC"] = intf["description"].(string)
+	nvPairMap["ADMIN_STATE"] = intf["admin_state"].(bool)
+	nvPairMap["MTU"] = intf["mtu"].(string)
+	nvPairMap["ALLOWED_VLANS"] = intf["allowed_vlans"].(string)
+	nvPairMap["ACCESS_VLAN"] = intf["access_vlans"].(string)
+	nvPairMap["CONF"] = intf["configuration"].(string)
+	for key, val := range intf["nv_pairs"].(map[string]interface{}) {
+		nvPairMap[key] = val.(string)
+	}
+
+	return &interfacePort{
+		serialNumber: serialNum,
+		name:         name,
+		policy:       intf["policy"].(string),
+		nvPairs:      nvPairMap,
+	}, nil
+}
+
+func getInterfacePorts(d *schema.ResourceData, intfs []interface{}, serials map[string]string) ([]*interfacePort, error) {
+	fabricName := d.Get("fabric_name").(string)
+
+	ports := make([]*interfacePort, 0, len(intfs))
+	for _, val := range intfs {
+		port, err := newInterfacePort(val.(map[string]interface{}), serials, fabricName)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// resetInterfacePorts returns the ports set back on the default policy, with
+// no description and admin up.
+func resetInterfacePorts(d *schema.ResourceData, ports []*interfacePort) []*interfacePort {
+	resets := make([]*interfacePort, 0, len(ports))
+	for _, port := range ports {
+		nvPairMap := make(map[string]interface{})
+		nvPairMap["INTF_NAME"] = port.name
+		nvPairMap["DESC"] = ""
+		nvPairMap["CONF"] = ""
+		nvPairMap["ADMIN_STATE"] = true
+		for key, val := range d.Get("default_nv_pairs").(map[string]interface{}) {
+			nvPairMap[key] = val.(string)
+		}
+
+		resets = append(resets, &interfacePort{
+			serialNumber: port.serialNumber,
+			name:         port.name,
+			policy:       d.Get("default_policy").(string),
+			nvPairs:      nvPairMap,
+		})
+	}
+	return resets
+}
+
+// saveInterfacePorts updates all of the ports with one request per policy,
+// DCNM taking a single policy per request. The ports always exist on the
+// switches, so they are never created.
+func saveInterfacePorts(client *Client, fabric string, ports []*interfacePort) error {
+	policies := make([]string, 0, 1)
+	byPolicy := make(map[string][]models.InterfaceConfig)
+	for _, port := range ports {
+		if _, ok := byPolicy[port.policy]; !ok {
+			policies = append(policies, port.policy)
+		}
+		byPolicy[port.policy] = append(byPolicy[port.policy], models.InterfaceConfig{
+			SerialNumber:  port.serialNumber,
+			InterfaceType: "INTERFACE_ETHERNET",
+			InterfaceName: port.name,
+			Fabric:        fabric,
+			NVPairs:       port.nvPairs,
+		})
+	}
+
+	for _, policy := range policies {
+		intf := models.Interface{}
+		intf.Policy = policy
+		intf.Type = "INTERFACE_ETHERNET"
+		intf.Interfaces = byPolicy[policy]
+
+		cont, err := client.Update("/rest/interface", &intf)
+		if err != nil {
+			if cont != nil {
+				if _, ok := cont.Data().([]interface{}); ok {
+					if errorMsg, flag := checkIntfErrors(cont); flag {
+						return fmt.Errorf(errorMsg)
+					}
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// getPortsStatus returns the compliance status of the ports of the switch by
+// port name, from a single request for all of the ports.
+func getPortsStatus(client *Client, serialNum string) (map[string]string, error) {
+	cont, err := getRemoteInterfaces(client, serialNum)
+	if err != nil {
+		return nil, err
+	}
+
+	intfs, ok := cont.Data().([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected interface status of switch %s : %s", serialNum, cont.String())
+	}
+
+	status := make(map[string]string)
+	for i := 0; i < len(intfs); i++ {
+		intfCont := cont.Index(i)
+		status[strings.ToLower(stripQuotes(intfCont.S("ifName").String()))] = stripQuotes(intfCont.S("complianceStatus").String())
+	}
+	return status, nil
+}
+
+// deployInterfacePorts deploys all of the ports with a single request, and
+// waits for them with one status request per switch.
+func deployInterfacePorts(ctx context.Context, client *Client, ports []*interfacePort, timeout time.Duration) error {
+	if len(ports) == 0 {
+		return nil
+	}
+
+	intfDeploy := make([]models.Model, 0, len(ports))
+	for _, port := range ports {
+		intfDeploy = append(intfDeploy, &models.InterfaceDelete{
+			SerialNumber: port.serialNumber,
+			Name:         port.name,
+		})
+	}
+
+	if _, err := client.SaveList("/rest/interface/deploy", intfDeploy); err != nil {
+		return err
+	}
+
+	return waitForDeployment(ctx, timeout, func() (bool, error) {
+		statuses := make(map[string]map[string]string)
+		for _, port := range ports {
+			status, ok := statuses[port.serialNumber]
+			if !ok {
+				var err error
+				status, err = getPortsStatus(client, port.serialNumber)
+				if err != nil {
+					return false, err
+				}
+				statuses[port.serialNumber] = status
+			}
+			if status[strings.ToLower(port.name)] != "In-Sync" {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// remoteInterface is an interface of the switch, along with the policy of the
+// group it is returned in.
+type remoteInterface struct {
+	policy string
+	port   *container.Container
+}
+
+// getRemoteSwitchInterfaces returns the policy and the template parameters of
+// the interfaces of the switch by interface name, from a single request.
+func getRemoteSwitchInterfaces(client *Client, serialNum string) (map[string]remoteInterface, error) {
+	durl := fmt.Sprintf("/rest/interface?serialNumber=%s", serialNum)
+	cont, err := client.GetviaURL(durl)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, ok := cont.Data().([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected interface list of switch %s : %s", serialNum, cont.String())
+	}
+
+	intfs := make(map[string]remoteInterface)
+	for i := 0; i < len(policies); i++ {
+		intfCont := cont.Index(i)
+		policy := stripQuotes(intfCont.S("policy").String())
+		ports, _ := intfCont.S("interfaces").Data().([]interface{})
+		for j := 0; j < len(ports); j++ {
+			port := intfCont.S("interfaces").Index(j)
+			name := stripQuotes(port.S("ifName").String())
+			intfs[strings.ToLower(name)] = remoteInterface{
+				policy: policy,
+				port:   port,
+			}
+		}
+	}
+	return intfs, nil
+}
+
+func resourceDCNMInterfacesCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Create method ")
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+
+	serials, err := getSwitchSerials(dcnmClient, fabricName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ports, err := getInterfacePorts(d, d.Get("interface").(*schema.Set).List(), serials)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = saveInterfacePorts(dcnmClient, fabricName, ports)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(resource.PrefixedUniqueId(fabricName + ":"))
+
+	if d.Get("deploy").(bool) {
+		log.Println("[DEBUG] Begining Deployment ", d.Id())
+
+		err = deployInterfacePorts(ctx, dcnmClient, ports, d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			d.Set("deploy", false)
+			return append(resourceDCNMInterfacesRead(ctx, d, m), deployWarning("interfaces are configured but failed to deploy", err))
+		}
+
+		log.Println("[DEBUG] End of Deployment ", d.Id())
+	}
+
+	log.Println("[DEBUG] End of Create method ", d.Id())
+	return resourceDCNMInterfacesRead(ctx, d, m)
+}
+
+func resourceDCNMInterfacesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	serials, err := getSwitchSerials(dcnmClient, d.Get("fabric_name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	remotes := make(map[string]map[string]remoteInterface)
+	statuses := make(map[string]map[string]string)
+
+	intfs := d.Get("interface").(*schema.Set).List()
+	intfGet := make([]interface{}, 0, len(intfs))
+	inSync := true
+	for _, val := range intfs {
+		intf := val.(map[string]interface{})
+		switchName := intf["switch_name"].(string)
+		name := intf["name"].(string)
+
+		serialNum, ok := serials[switchName]
+		if !ok {
+			log.Printf("[WARN] Switch %s of interface %s not found, removing the interface from state", switchName, name)
+			continue
+		}
+
+		if _, ok := remotes[serialNum]; !ok {
+			remote, err := getRemoteSwitchInterfaces(dcnmClient, serialNum)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			remotes[serialNum] = remote
+
+			status, err := getPortsStatus(dcnmClient, serialNum)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			statuses[serialNum] = status
+		}
+
+		remote, ok := remotes[serialNum][strings.ToLower(name)]
+		if !ok {
+			log.Printf("[WARN] Interface %s not found on switch %s, removing it from state", name, switchName)
+			continue
+		}
+		port := remote.port
+
+		// fields which the policy of the port does not have are read empty.
+		get := func(key string) string {
+			if !port.Exists("nvPairs", key) {
+				return ""
+			}
+			return stripQuotes(port.S("nvPairs", key).String())
+		}
+		// only the fields set for the port are tracked, reading the others
+		// would change the hash of the port.
+		track := func(field, key string) string {
+			if intf[field].(string) == "" {
+				return ""
+			}
+			return get(key)
+		}
+
+		intfMap := map[string]interface{}{
+			"switch_name":       switchName,
+			"name":              name,
+			"policy":            remote.policy,
+			"description":       track("description", "DESC"),
+			"admin_state":       intf["admin_state"],
+			"mtu":               track("mtu", "MTU"),
+			"allowed_vlans":     track("allowed_vlans", "ALLOWED_VLANS"),
+			"access_vlans":      track("access_vlans", "ACCESS_VLAN"),
+			"configuration":     track("configuration", "CONF"),
+			"serial_number":     serialNum,
+			"compliance_status": statuses[serialNum][strings.ToLower(name)],
+		}
+		if state, err := strconv.ParseBool(get("ADMIN_STATE")); err == nil {
+			intfMap["admin_state"] = state
+		}
+
+		// only the keys managed through nv_pairs are tracked.
+		nvGet := make(map[string]interface{})
+		for key := range intf["nv_pairs"].(map[string]interface{}) {
+			if port.Exists("nvPairs", key) {
+				nvGet[key] = get(key)
+			}
+		}
+		intfMap["nv_pairs"] = nvGet
+
+		if intfMap["compliance_status"] != "In-Sync" {
+			log.Printf("[WARN] Interface %s of switch %s is %s", name, switchName, intfMap["compliance_status"])
+			inSync = false
+		}
+		intfGet = append(intfGet, intfMap)
+	}
+	d.Set("interface", intfGet)
+
+	if d.Get("deploy").(bool) && !inSync {
+		d.Set("deploy", false)
+	}
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
+
+// interfacesChanged returns the ports of the new configuration which differ
+// from the old one, and the ports which are not configured anymore.
+func interfacesChanged(oldIntfs, newIntfs []interface{}) ([]interface{}, []interface{}) {
+	// the computed fields tell nothing about the configuration of the port.
+	config := func(intf map[string]interface{}) map[string]interface{} {
+		conf := make(map[string]interface{})
+		for key, val := range intf {
+			if key != "serial_number" && key != "compliance_status" {
+				conf[key] = val
+			}
+		}
+		conf["name"] = strings.ToLower(intf["name"].(string))
+		return conf
+	}
+
+	olds := make(map[string]map[string]interface{})
+	for _, val := range oldIntfs {
+		intf := val.(map[string]interface{})
+		olds[interfacePortKey(intf)] = intf
+	}
+
+	changed := make([]interface{}, 0, 1)
+	news := make(map[string]bool)
+	for _, val := range newIntfs {
+		intf := val.(map[string]interface{})
+		news[interfacePortKey(intf)] = true
+		if old, ok := olds[interfacePortKey(intf)]; !ok || !reflect.DeepEqual(config(old), config(intf)) {
+			changed = append(changed, intf)
+		}
+	}
+
+	removed := make([]interface{}, 0, 1)
+	for _, val := range oldIntfs {
+		intf := val.(map[string]interface{})
+		if !news[interfacePortKey(intf)] {
+			removed = append(removed, intf)
+		}
+	}
+	return changed, removed
+}
+
+func resourceDCNMInterfacesUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Update method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+
+	serials, err := getSwitchSerials(dcnmClient, fabricName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	oldIntfs, newIntfs := d.GetChange("interface")
+	changedIntfs, removedIntfs := interfacesChanged(oldIntfs.(*schema.Set).List(), newIntfs.(*schema.Set).List())
+
+	changed, err := getInterfacePorts(d, changedIntfs, serials)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	removed, err := getInterfacePorts(d, removedIntfs, serials)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	resets := resetInterfacePorts(d, removed)
+
+	// the removed ports go in the same requests as the changed ones.
+	ports := append(changed, resets...)
+	if len(ports) > 0 {
+		err = saveInterfacePorts(dcnmClient, fabricName, ports)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.Get("deploy").(bool) {
+		log.Println("[DEBUG] Begining Deployment ", d.Id())
+
+		// all of the ports are deployed again when some are out of sync.
+		if d.HasChange("deploy") {
+			all, err := getInterfacePorts(d, newIntfs.(*schema.Set).List(), serials)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			ports = append(all, resets...)
+		}
+
+		err = deployInterfacePorts(ctx, dcnmClient, ports, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			d.Set("deploy", false)
+			return append(resourceDCNMInterfacesRead(ctx, d, m), deployWarning("interfaces are configured but failed to deploy", err))
+		}
+
+		log.Println("[DEBUG] End of Deployment ", d.Id())
+	}
+
+	log.Println("[DEBUG] End of Update method ", d.Id())
+	return resourceDCNMInterfacesRead(ctx, d, m)
+}
+
+func resourceDCNMInterfacesDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Delete method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+
+	serials, err := getSwitchSerials(dcnmClient, fabricName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ports, err := getInterfacePorts(d, d.Get("interface").(*schema.Set).List(), serials)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// ethernet ports can not be deleted, they are reset instead.
+	resets := resetInterfacePorts(d, ports)
+	err = saveInterfacePorts(dcnmClient, fabricName, resets)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.Get("deploy").(bool) {
+		err = deployInterfacePorts(ctx, dcnmClient, resets, d.Timeout(schema.TimeoutDelete))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+
+	log.Println("[DEBUG] End of Delete method ")
+	return nil
+}
@@ -0,0 +1,150 @@
+package dcnm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestDCNMInterfaces_MockBulk(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInterfaces()
+
+	leaf1 := testMockSerial(t, dcnmClient, "leaf1")
+	leaf2 := testMockSerial(t, dcnmClient, "leaf2")
+
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"interface": []interface{}{
+			map[string]interface{}{
+				"switch_name":  "leaf1",
+				"name":         "Ethernet1/3",
+				"policy":       "int_access_host_11_1",
+				"access_vlans": "10",
+				"description":  "host a",
+			},
+			map[string]interface{}{
+				"switch_name": "leaf2",
+				"name":        "Ethernet1/4",
+				"policy":      "int_access_host_11_1",
+				"description": "host b",
+			},
+			// not the first port of its policy, which DCNM returns grouped.
+			map[string]interface{}{
+				"switch_name":   "leaf2",
+				"name":          "Ethernet1/2",
+				"policy":        "int_trunk_host_11_1",
+				"allowed_vlans": "100-200",
+				"description":   "uplink",
+			},
+		},
+	}
+
+	// one request per policy and a single deploy for all of the ports.
+	puts := testMockServer.callCount("PUT", "/rest/interface")
+	deploys := testMockServer.callCount("POST", "/rest/interface/deploy")
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	if got := testMockServer.callCount("PUT", "/rest/interface") - puts; got != 2 {
+		t.Fatalf("expected 2 interface requests, got %d", got)
+	}
+	if got := testMockServer.callCount("POST", "/rest/interface/deploy") - deploys; got != 1 {
+		t.Fatalf("expected 1 deploy request, got %d", got)
+	}
+
+	if !strings.HasPrefix(state.ID, "fab1:") {
+		t.Fatalf("expected an id unique in fab1, got : %s", state.ID)
+	}
+	testMockCheckAttr(t, state, "deploy", "true")
+	testMockCheckAttr(t, state, "interface.#", "3")
+	testInterfacesCheckAttr(t, state, "leaf1", "Ethernet1/3", "serial_number", leaf1)
+	testInterfacesCheckAttr(t, state, "leaf1", "Ethernet1/3", "access_vlans", "10")
+	testInterfacesCheckAttr(t, state, "leaf1", "Ethernet1/3", "compliance_status", "In-Sync")
+	testInterfacesCheckAttr(t, state, "leaf2", "Ethernet1/4", "serial_number", leaf2)
+	testInterfacesCheckAttr(t, state, "leaf2", "Ethernet1/2", "allowed_vlans", "100-200")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	// the drift of a port is reported on that port, and only it is updated.
+	testMockServer.outOfBand(func() {
+		testMockServer.interfaces[mockIntfKey(leaf2, "Ethernet1/4")]["nvPairs"].(map[string]interface{})["DESC"] = "changed"
+	})
+	state = testMockRefresh(t, r, state, dcnmClient)
+	testInterfacesCheckAttr(t, state, "leaf2", "Ethernet1/4", "description", "changed")
+	testMockPlanChanged(t, r, state, raw, dcnmClient)
+
+	puts = testMockServer.callCount("PUT", "/rest/interface")
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	if got := testMockServer.callCount("PUT", "/rest/interface") - puts; got != 1 {
+		t.Fatalf("expected 1 interface request, got %d", got)
+	}
+	testInterfacesCheckAttr(t, state, "leaf2", "Ethernet1/4", "description", "host b")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	// ports out of sync are deployed again.
+	testMockServer.outOfBand(func() {
+		testMockServer.intfDeployed[mockIntfKey(leaf1, "Ethernet1/3")] = false
+	})
+	state = testMockRefresh(t, r, state, dcnmClient)
+	testMockCheckAttr(t, state, "deploy", "false")
+	testInterfacesCheckAttr(t, state, "leaf1", "Ethernet1/3", "compliance_status", "Pending")
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "deploy", "true")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	// a port added in front of the others leaves them alone.
+	raw["interface"] = append([]interface{}{
+		map[string]interface{}{
+			"switch_name": "leaf1",
+			"name":        "Ethernet1/4",
+			"policy":      "int_access_host_11_1",
+		},
+	}, raw["interface"].([]interface{})...)
+	puts = testMockServer.callCount("PUT", "/rest/interface")
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	if got := testMockServer.callCount("PUT", "/rest/interface") - puts; got != 1 {
+		t.Fatalf("expected 1 interface request, got %d", got)
+	}
+	testMockCheckAttr(t, state, "interface.#", "4")
+	testInterfacesCheckAttr(t, state, "leaf1", "Ethernet1/3", "access_vlans", "10")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	// removed ports are reset to the default policy.
+	raw["interface"] = raw["interface"].([]interface{})[1:3]
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "interface.#", "2")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	testMockServer.outOfBand(func() {
+		intf := testMockServer.interfaces[mockIntfKey(leaf2, "Ethernet1/2")]
+		if intf["policy"] != "int_trunk_host_11_1" || intf["nvPairs"].(map[string]interface{})["DESC"] != "" {
+			t.Fatalf("expected Ethernet1/2 reset, got : %v", intf)
+		}
+	})
+
+	testMockDestroy(t, r, state, dcnmClient)
+	testMockServer.outOfBand(func() {
+		for _, key := range []string{mockIntfKey(leaf1, "Ethernet1/3"), mockIntfKey(leaf1, "Ethernet1/4"), mockIntfKey(leaf2, "Ethernet1/4")} {
+			intf := testMockServer.interfaces[key]
+			if intf["policy"] != "int_trunk_host_11_1" || !testMockServer.intfDeployed[key] {
+				t.Fatalf("expected %s reset and deployed, got : %v", key, intf)
+			}
+		}
+	})
+}
+
+// testInterfacesCheckAttr checks an attribute of the port of the switch, the
+// ports being stored by the hash of their configuration.
+func testInterfacesCheckAttr(t *testing.T, state *terraform.InstanceState, switchName, name, key, value string) {
+	t.Helper()
+
+	for attr, val := range state.Attributes {
+		if !strings.HasPrefix(attr, "interface.") || !strings.HasSuffix(attr, ".name") || val != name {
+			continue
+		}
+		prefix := strings.TrimSuffix(attr, "name")
+		if state.Attributes[prefix+"switch_name"] == switchName {
+			testMockCheckAttr(t, state, prefix+key, value)
+			return
+		}
+	}
+	t.Fatalf("expected interface %s of switch %s in state", name, switchName)
+}
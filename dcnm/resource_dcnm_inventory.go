@@ -1,30 +1,38 @@
 package dcnm
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/container"
 	"github.com/ciscoecosystem/dcnm-go-client/models"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceDCNMInventroy() *schema.Resource {
-	return &schema.Resource{
-		Create: resourceDCNMInventroyCreate,
-		Update: resourceDCNMInventroyUpdate,
-		Read:   resourceDCNMInventroyRead,
-		Delete: resourceDCNMInventroyDelete,
+	r := &schema.Resource{
+		CreateContext: resourceDCNMInventroyCreate,
+		UpdateContext: resourceDCNMInventroyUpdate,
+		ReadContext:   resourceDCNMInventroyRead,
+		DeleteContext: resourceDCNMInventroyDelete,
 
 		Importer: &schema.ResourceImporter{
-			State: resourceDCNMInventoryImporter,
+			StateContext: resourceDCNMInventoryImporter,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		SchemaVersion: 1,
+
 		Schema: map[string]*schema.Schema{
 			"fabric_name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -135,19 +143,39 @@ func resourceDCNMInventroy() *schema.Resource {
 				Default:  true,
 			},
 
+			// config_timeout is used as the create and update timeout when set.
 			"config_timeout": &schema.Schema{
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  5,
+				Type:       schema.TypeInt,
+				Optional:   true,
+				Deprecated: "use the timeouts block instead",
 			},
 		},
 	}
+
+	// the version 0 schema only differs by the default of config_timeout,
+	// its state has the same type.
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    r.CoreConfigSchema().ImpliedType(),
+			Upgrade: resourceDCNMInventoryStateUpgradeV0,
+		},
+	}
+	return r
 }
 
-func extractFabricID(dcnmClient *client.Client, fabricName string) (int, error) {
-	durl := fmt.Sprintf("/rest/control/fabrics/%s", fabricName)
+// resourceDCNMInventoryStateUpgradeV0 clears config_timeout when it holds the
+// former default of 5, so the timeouts block is used instead. A value of 5
+// which is still configured is set again on the next apply.
+func resourceDCNMInventoryStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if timeout, ok := rawState["config_timeout"].(float64); ok && timeout == 5 {
+		delete(rawState, "config_timeout")
+	}
+	return rawState, nil
+}
 
-	cont, err := dcnmClient.GetviaURL(durl)
+func extractFabricID(dcnmClient *Client, fabricName string) (int, error) {
+	cont, err := getRemoteFabric(dcnmClient, fabricName)
 	if err != nil {
 		return 0, err
 	}
@@ -194,7 +222,7 @@ func extractSerialNumber(cont *container.Container, ip string) (string, error) {
 	return "", fmt.Errorf("No inventory found for given ip address")
 }
 
-func getRemoteSwitch(dcnmClient *client.Client, fabric, ip string) (*container.Container, error) {
+func getRemoteSwitch(dcnmClient *Client, fabric, ip string) (*container.Container, error) {
 	durl := fmt.Sprintf("/rest/control/fabrics/%s/inventory", fabric)
 
 	cont, err := dcnmClient.GetviaURL(durl)
@@ -210,7 +238,7 @@ func getRemoteSwitch(dcnmClient *client.Client, fabric, ip string) (*container.C
 			return switchCont, nil
 		}
 	}
-	return nil, fmt.Errorf("Desired switch not found")
+	return nil, notFoundError("Desired switch not found")
 }
 
 func setSwitchAttributes(d *schema.ResourceData, cont *container.Container) *schema.ResourceData {
@@ -228,10 +256,10 @@ func setSwitchAttributes(d *schema.ResourceData, cont *container.Container) *sch
 	return d
 }
 
-func resourceDCNMInventoryImporter(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+func resourceDCNMInventoryImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
 	log.Println("[DEBUG] Begining Importer ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	importInfo := strings.Split(d.Id(), ":")
 	if len(importInfo) != 2 {
@@ -259,10 +287,10 @@ func resourceDCNMInventoryImporter(d *schema.ResourceData, m interface{}) ([]*sc
 	return []*schema.ResourceData{importState}, nil
 }
 
-func resourceDCNMInventroyCreate(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMInventroyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Create method ")
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	fabricName := d.Get("fabric_name").(string)
 	ip := d.Get("ip").(string)
@@ -296,19 +324,19 @@ func resourceDCNMInventroyCreate(d *schema.ResourceData, m interface{}) error {
 
 	fabricID, err := extractFabricID(dcnmClient, fabricName)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	dUrl := fmt.Sprintf("/rest/control/fabrics/%s/inventory/test-reachability", strconv.Itoa(fabricID))
 	cont, err := dcnmClient.Save(dUrl, &inv)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	switchM := extractSwitchinfo(cont)
 
 	if switchM.Selectable != "true" || switchM.Reachable != "true" {
-		return fmt.Errorf("Desired switch is not reachable or not selectable or invalid user/password or bad authentication protocol")
+		return diag.Errorf("Desired switch is not reachable or not selectable or invalid user/password or bad authentication protocol")
 	}
 
 	invModel := models.NewSwitch(&inv, &switchM)
@@ -316,38 +344,27 @@ func resourceDCNMInventroyCreate(d *schema.ResourceData, m interface{}) error {
 	dUrl = fmt.Sprintf("/rest/control/fabrics/%s/inventory/discover", fabricName)
 	_, err = dcnmClient.Save(dUrl, invModel)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(ip)
 
-	var serialNum string
-	if d.Get("deploy").(bool) == true {
-		var configTimeout int
-		for i := 0; i < 3; i++ {
-			cont, err = getRemoteSwitch(dcnmClient, fabricName, ip)
-			if err != nil {
-				return err
-			}
-			serialNum = stripQuotes(cont.S("serialNumber").String())
-			configTimeout = d.Get("config_timeout").(int)
-			log.Println(stripQuotes(cont.S("mode").String()))
-			if stripQuotes(cont.S("mode").String()) != "Migration" {
-				time.Sleep(10 * time.Second)
-				break
-			}
-			time.Sleep(5 * time.Second)
-		}
+	deadline := time.Now().Add(inventoryTimeout(d, schema.TimeoutCreate))
+	serialNum, err := waitForSwitchDiscovery(ctx, dcnmClient, fabricName, ip, time.Until(deadline))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-		err = deployswitch(dcnmClient, fabricName, serialNum, configTimeout)
+	if d.Get("deploy").(bool) == true {
+		err = deployswitch(ctx, dcnmClient, fabricName, serialNum, time.Until(deadline))
 		if err != nil {
 			durl := fmt.Sprintf("/rest/control/fabrics/%s/switches/%s", fabricName, serialNum)
 			_, delerr := dcnmClient.Delete(durl)
 			if delerr != nil {
-				return delerr
+				return diag.FromErr(delerr)
 			}
 			d.SetId("")
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -359,18 +376,27 @@ func resourceDCNMInventroyCreate(d *schema.ResourceData, m interface{}) error {
 
 		_, err := dcnmClient.SaveForAttachment(durl, &sRole)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	log.Println("[DEBUG] End of Create method ", d.Id())
-	return resourceDCNMInventroyRead(d, m)
+	return resourceDCNMInventroyRead(ctx, d, m)
+}
+
+// inventoryTimeout returns the timeout of the operation, which the deprecated
+// config_timeout, in minutes, overrides until it is removed.
+func inventoryTimeout(d *schema.ResourceData, key string) time.Duration {
+	if timeout, ok := d.GetOk("config_timeout"); ok {
+		return time.Duration(timeout.(int)) * time.Minute
+	}
+	return d.Timeout(key)
 }
 
-func resourceDCNMInventroyUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMInventroyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Update method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	fabricName := d.Get("fabric_name").(string)
 
@@ -383,7 +409,7 @@ func resourceDCNMInventroyUpdate(d *schema.ResourceData, m interface{}) error {
 
 		cont, err := getRemoteSwitch(dcnmClient, fabricName, ip)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 
 		switchDbID := stripQuotes(cont.S("switchDbID").String())
@@ -393,35 +419,23 @@ func resourceDCNMInventroyUpdate(d *schema.ResourceData, m interface{}) error {
 		durl := fmt.Sprintf("/fm/fmrest/lanConfig/saveSwitchCredentials")
 		cont, err = dcnmClient.UpdateCred(durl, body)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	d.SetId(ip)
 
-	var serialNum string
-	if d.HasChange("deploy") && d.Get("deploy").(bool) == false {
-		d.Set("deploy", true)
-		return fmt.Errorf("Deployed switch can not be undeployed")
-	} else {
-		var configTimeout int
-		for i := 0; i < 3; i++ {
-			cont, err := getRemoteSwitch(dcnmClient, fabricName, ip)
-			if err != nil {
-				return err
-			}
-			serialNum = stripQuotes(cont.S("serialNumber").String())
-			configTimeout = d.Get("config_timeout").(int)
-			if stripQuotes(cont.S("mode").String()) != "Migration" {
-				break
-			}
-			time.Sleep(5 * time.Second)
-		}
+	deadline := time.Now().Add(inventoryTimeout(d, schema.TimeoutUpdate))
+	serialNum, err := waitForSwitchDiscovery(ctx, dcnmClient, fabricName, ip, time.Until(deadline))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-		err := deployswitch(dcnmClient, fabricName, serialNum, configTimeout)
+	if d.Get("deploy").(bool) == true {
+		err = deployswitch(ctx, dcnmClient, fabricName, serialNum, time.Until(deadline))
 		if err != nil {
 			d.Set("deploy", false)
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -433,42 +447,47 @@ func resourceDCNMInventroyUpdate(d *schema.ResourceData, m interface{}) error {
 
 		_, err := dcnmClient.SaveForAttachment(durl, &sRole)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	log.Println("[DEBUG] End of Update method ", d.Id())
-	return resourceDCNMInventroyRead(d, m)
+	return resourceDCNMInventroyRead(ctx, d, m)
 }
 
-func resourceDCNMInventroyRead(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMInventroyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Read method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	fabricName := d.Get("fabric_name").(string)
 	dn := d.Id()
 
 	cont, err := getRemoteSwitch(dcnmClient, fabricName, dn)
 	if err != nil {
-		return err
+		if isNotFound(err) {
+			log.Printf("[WARN] Switch %s not found, removing from state", dn)
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 
 	setSwitchAttributes(d, cont)
 
-	flag, err := checkDeploy(dcnmClient, fabricName, d.Get("serial_number").(string))
-	if err != nil {
-		return err
-	}
-	if flag {
-		d.Set("deploy", true)
-	} else {
-		d.Set("deploy", false)
+	// with deploy=false the switch is deployed by dcnm_deployment, its
+	// deployment is not tracked here.
+	if d.Get("deploy").(bool) {
+		flag, err := checkDeploy(dcnmClient, fabricName, d.Get("serial_number").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set("deploy", flag)
 	}
 
 	role, err := getSwitchRole(dcnmClient, d.Get("serial_number").(string))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.Set("role", role)
 
@@ -476,10 +495,10 @@ func resourceDCNMInventroyRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceDCNMInventroyDelete(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMInventroyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Delete method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	fabricName := d.Get("fabric_name").(string)
 
@@ -488,18 +507,18 @@ func resourceDCNMInventroyDelete(d *schema.ResourceData, m interface{}) error {
 	durl := fmt.Sprintf("/rest/control/fabrics/%s/inventory", fabricName)
 	cont, err := dcnmClient.GetviaURL(durl)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	serialNumber, err := extractSerialNumber(cont, dn)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	durl = fmt.Sprintf("/rest/control/fabrics/%s/switches/%s", fabricName, serialNumber)
 	_, err = dcnmClient.Delete(durl)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId("")
 
@@ -507,8 +526,8 @@ func resourceDCNMInventroyDelete(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func checkDeploy(client *client.Client, fabric, serialNum string) (bool, error) {
-	durl := fmt.Sprintf("rest/control/fabrics/%s/config-preview/%s", fabric, serialNum)
+func checkDeploy(client *Client, fabric, serialNum string) (bool, error) {
+	durl := fmt.Sprintf("/rest/control/fabrics/%s/config-preview/%s", fabric, serialNum)
 	cont, err := client.GetviaURL(durl)
 	if err != nil {
 		return false, err
@@ -527,99 +546,128 @@ func checkDeploy(client *client.Client, fabric, serialNum string) (bool, error)
 	return false, nil
 }
 
-func deployswitch(client *client.Client, fabric, serialNum string, configTime int) error {
-	log.Println("[DEBUG] Begining Deployment of switch ", serialNum)
+// waitForSwitchDiscovery waits until a discovered switch has left the
+// migration mode and returns its serial number.
+func waitForSwitchDiscovery(ctx context.Context, client *Client, fabric, ip string, timeout time.Duration) (string, error) {
+	var serialNum string
+	err := waitForDeployment(ctx, timeout, func() (bool, error) {
+		cont, err := getRemoteSwitch(client, fabric, ip)
+		if err != nil {
+			return false, err
+		}
+		serialNum = stripQuotes(cont.S("serialNumber").String())
+		return stripQuotes(cont.S("mode").String()) != "Migration", nil
+	})
+	return serialNum, err
+}
+
+// getSwitchConfigStatuses returns the configuration status of every switch of
+// the fabric, keyed by serial number.
+func getSwitchConfigStatuses(client *Client, fabric string) (map[string]string, error) {
+	durl := fmt.Sprintf("/rest/control/fabrics/%s/config-preview", fabric)
+	cont, err := client.GetviaURL(durl)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]string)
+	totalSwitch := len(cont.Data().([]interface{}))
+	for i := 0; i < totalSwitch; i++ {
+		switchCont := cont.Index(i)
+		statuses[stripQuotes(switchCont.S("switchId").String())] = stripQuotes(switchCont.S("status").String())
+	}
+	return statuses, nil
+}
+
+func deployswitch(ctx context.Context, client *Client, fabric, serialNum string, timeout time.Duration) error {
+	return deploySwitches(ctx, client, fabric, []string{serialNum}, timeout)
+}
+
+// deploySwitches runs the switch deployment sequence once for all of the
+// given switches, skipping the ones already in sync.
+func deploySwitches(ctx context.Context, client *Client, fabric string, serials []string, timeout time.Duration) error {
+	log.Println("[DEBUG] Begining Deployment of switches ", serials)
+
+	deadline := time.Now().Add(timeout)
 
 	// Step 1 switch configuration
-	configDone := false
-	timeLeft := configTime
-	durl := fmt.Sprintf("rest/control/fabrics/%s/config-preview", fabric)
-	for timeLeft > 0 {
-		cont, err := client.GetviaURL(durl)
+	pending := make([]string, 0, len(serials))
+	err := waitForDeployment(ctx, timeout, func() (bool, error) {
+		statuses, err := getSwitchConfigStatuses(client, fabric)
 		if err != nil {
-			return err
+			return false, err
 		}
 
-		var flag bool
-		totalSwitch := len(cont.Data().([]interface{}))
-		for i := 0; i < totalSwitch; i++ {
-			switchCont := cont.Index(i)
-			if stripQuotes(switchCont.S("switchId").String()) == serialNum {
-				if stripQuotes(switchCont.S("status").String()) == "Out-of-Sync" {
-					flag = true
-					configDone = true
-				} else if stripQuotes(switchCont.S("status").String()) == "In-Sync" {
-					return nil
-				}
-				break
+		pending = pending[:0]
+		for _, serialNum := range serials {
+			status := statuses[serialNum]
+			if status != "Out-of-Sync" && status != "In-Sync" {
+				return false, nil
+			}
+			if status == "Out-of-Sync" {
+				pending = append(pending, serialNum)
 			}
 		}
-
-		if flag {
-			break
-		}
-
-		timeLeft = timeLeft / 2
-		time.Sleep(time.Duration(timeLeft) * time.Minute)
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("Timeout occurs before completion of switch configuration : %s", err)
 	}
-	if !configDone {
-		return fmt.Errorf("Timeout occurs before completion of switch configuration")
+	if len(pending) == 0 {
+		return nil
 	}
 
-	//Step 2 deploy switch into fabric
-	durl = fmt.Sprintf("rest/control/fabrics/%s/config-deploy/%s", fabric, serialNum)
-	_, err := client.SaveAndDeploy(durl)
+	//Step 2 deploy switches into fabric
+	durl := fmt.Sprintf("/rest/control/fabrics/%s/config-deploy/%s", fabric, strings.Join(pending, ","))
+	_, err = client.SaveAndDeploy(durl)
 	if err != nil {
 		return err
 	}
 
 	//Step 3 deploy fabric
-	durl = fmt.Sprintf("rest/control/fabrics/%s/config-deploy", fabric)
+	durl = fmt.Sprintf("/rest/control/fabrics/%s/config-deploy", fabric)
 	_, err = client.SaveAndDeploy(durl)
 	if err != nil {
 		return err
 	}
 
 	//Step 4 Save configuration
-	durl = fmt.Sprintf("rest/control/fabrics/%s/config-save", fabric)
+	durl = fmt.Sprintf("/rest/control/fabrics/%s/config-save", fabric)
 	_, err = client.SaveAndDeploy(durl)
 	if err != nil {
 		return err
 	}
 
 	//Step 5 deploy fabric
-	durl = fmt.Sprintf("rest/control/fabrics/%s/config-deploy", fabric)
+	durl = fmt.Sprintf("/rest/control/fabrics/%s/config-deploy", fabric)
 	_, err = client.SaveAndDeploy(durl)
 	if err != nil {
 		return err
 	}
 
 	//Step 6 check deployment
-	durl = fmt.Sprintf("rest/control/fabrics/%s/config-preview", fabric)
-	cont, err := client.GetviaURL(durl)
-	if err != nil {
-		return err
-	}
-	flag := false
-	totalSwitch := len(cont.Data().([]interface{}))
-	for i := 0; i < totalSwitch; i++ {
-		switchCont := cont.Index(i)
-		if stripQuotes(switchCont.S("switchId").String()) == serialNum {
-			if stripQuotes(switchCont.S("status").String()) == "In-Sync" {
-				flag = true
+	err = waitForDeployment(ctx, time.Until(deadline), func() (bool, error) {
+		statuses, err := getSwitchConfigStatuses(client, fabric)
+		if err != nil {
+			return false, err
+		}
+
+		for _, serialNum := range pending {
+			if statuses[serialNum] != "In-Sync" {
+				return false, nil
 			}
-			break
 		}
-	}
-	if !flag {
-		return fmt.Errorf("Switch deployment is not in sync")
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("Switch deployment is not in sync : %s", err)
 	}
 
-	log.Println("[DEBUG] End of Deployment of switch ", serialNum)
+	log.Println("[DEBUG] End of Deployment of switches ", serials)
 	return nil
 }
 
-func getSwitchRole(client *client.Client, serial string) (string, error) {
+func getSwitchRole(client *Client, serial string) (string, error) {
 	durl := fmt.Sprintf("/rest/control/switches/roles?serialNumber=%s", serial)
 	cont, err := client.GetviaURL(durl)
 	if err != nil {
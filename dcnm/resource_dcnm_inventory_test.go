@@ -1,10 +1,11 @@
 package dcnm
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/models"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -81,7 +82,7 @@ func testAccCheckDCNMInventoryExists(name string, inv *models.Inventory) resourc
 			return fmt.Errorf("No Inventory dn was set")
 		}
 
-		dcnmClient := (*providerfInv).Meta().(*client.Client)
+		dcnmClient := (*providerfInv).Meta().(*Client)
 
 		cont, err := dcnmClient.GetviaURL(fmt.Sprintf("/rest/control/fabrics/%s/inventory", "fab1"))
 		if err != nil {
@@ -110,7 +111,7 @@ func testAccCheckDCNMInventoryExists(name string, inv *models.Inventory) resourc
 }
 
 func testAccCheckDCNMInventoryDestroy(s *terraform.State) error {
-	dcnmClient := (*providerfInv).Meta().(*client.Client)
+	dcnmClient := (*providerfInv).Meta().(*Client)
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type == "dcnm_inventory" {
@@ -142,3 +143,124 @@ func testAccCheckDCNMInventoryAttributes(ip string, inv *models.Inventory) resou
 		return nil
 	}
 }
+
+func TestDCNMInventory_MockCRUD(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInventroy()
+
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"ip":          "10.0.0.10",
+		"username":    "admin",
+		"password":    "admin",
+		"deploy":      false,
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "id", "10.0.0.10")
+	testMockCheckAttr(t, state, "switch_name", "switch-10.0.0.10")
+	testMockCheckAttr(t, state, "deploy", "false")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["deploy"] = true
+	raw["role"] = "spine"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "deploy", "true")
+	testMockCheckAttr(t, state, "role", "spine")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	imported := testMockImport(t, r, "fab1:switch-10.0.0.10", dcnmClient)
+	testMockCheckAttr(t, imported, "ip", "10.0.0.10")
+	testMockCheckAttr(t, imported, "role", "spine")
+
+	testMockDestroy(t, r, state, dcnmClient)
+	if _, err := getRemoteSwitch(dcnmClient, "fab1", "10.0.0.10"); err == nil {
+		t.Fatalf("Switch still exists")
+	}
+}
+
+func TestDCNMInventory_ConfigTimeout(t *testing.T) {
+	r := resourceDCNMInventroy()
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"fabric_name": "fab1",
+		"ip":          "10.0.0.12",
+	})
+	d.SetId("10.0.0.12")
+	if got := inventoryTimeout(d, schema.TimeoutCreate); got != 20*time.Minute {
+		t.Fatalf("expected the default timeout of 20m, got %s", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"fabric_name":    "fab1",
+		"ip":             "10.0.0.12",
+		"config_timeout": 3,
+	})
+	if got := inventoryTimeout(d, schema.TimeoutUpdate); got != 3*time.Minute {
+		t.Fatalf("expected config_timeout to set a timeout of 3m, got %s", got)
+	}
+
+	// states written with the former default of 5 use the timeouts block.
+	state, err := resourceDCNMInventoryStateUpgradeV0(context.Background(), map[string]interface{}{"ip": "10.0.0.12", "config_timeout": float64(5)}, nil)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if _, ok := state["config_timeout"]; ok {
+		t.Fatalf("expected the default config_timeout to be cleared, got : %v", state)
+	}
+	state, err = resourceDCNMInventoryStateUpgradeV0(context.Background(), map[string]interface{}{"ip": "10.0.0.12", "config_timeout": float64(3)}, nil)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if state["config_timeout"] != float64(3) {
+		t.Fatalf("expected config_timeout of 3 to be kept, got : %v", state)
+	}
+}
+
+func TestDCNMInventory_MockDeletedOutOfBand(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMInventroy()
+
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"ip":          "10.0.0.11",
+		"username":    "admin",
+		"password":    "admin",
+		"deploy":      false,
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	durl := fmt.Sprintf("/rest/control/fabrics/fab1/switches/%s", state.Attributes["serial_number"])
+	if _, err := dcnmClient.Delete(durl); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	testMockRefreshGone(t, r, state, dcnmClient)
+}
+
+func TestDCNMInventory_MockListDataSource(t *testing.T) {
+	dcnmClient := testMockClient(t)
+
+	data := testMockReadData(t, datasourceDCNMSwitches(), map[string]interface{}{
+		"fabric_name": "fab1",
+		"name_regex":  "^leaf[12]$",
+		"role":        "leaf",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "switches.#", "2")
+	testMockCheckAttr(t, data, "switches.0.switch_name", "leaf1")
+	testMockCheckAttr(t, data, "switches.0.ip", "10.0.0.1")
+	testMockCheckAttr(t, data, "switches.0.role", "leaf")
+	testMockCheckAttr(t, data, "switches.1.serial_number", testMockSerial(t, dcnmClient, "leaf2"))
+
+	data = testMockReadData(t, datasourceDCNMSwitches(), map[string]interface{}{
+		"fabric_name": "fab1",
+		"role":        "spine",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "switches.#", "0")
+
+	data = testMockReadData(t, datasourceDCNMSwitches(), map[string]interface{}{
+		"fabric_name": "fab1",
+		"model":       "N9K-C9300v",
+		"name_regex":  "^leaf1$",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "switches.#", "1")
+}
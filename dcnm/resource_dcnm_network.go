@@ -1,6 +1,7 @@
 package dcnm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -8,21 +9,27 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/container"
 	"github.com/ciscoecosystem/dcnm-go-client/models"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceDCNMNetwork() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceDCNMNetworkCreate,
-		Update: resourceDCNMNetworkUpdate,
-		Read:   resourceDCNMNetworkRead,
-		Delete: resourceDCNMNetworkDelete,
+		CreateContext: resourceDCNMNetworkCreate,
+		UpdateContext: resourceDCNMNetworkUpdate,
+		ReadContext:   resourceDCNMNetworkRead,
+		DeleteContext: resourceDCNMNetworkDelete,
 
 		Importer: &schema.ResourceImporter{
-			State: resourceDCNMNetworkImporter,
+			StateContext: resourceDCNMNetworkImporter,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -274,7 +281,7 @@ func resourceDCNMNetwork() *schema.Resource {
 	}
 }
 
-func getRemoteNetwork(client *client.Client, fabric, name string) (*container.Container, error) {
+func getRemoteNetwork(client *Client, fabric, name string) (*container.Container, error) {
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s", fabric, name)
 
 	cont, err := client.GetviaURL(durl)
@@ -401,10 +408,10 @@ func setNetworkAttributes(d *schema.ResourceData, cont *container.Container) *sc
 	return d
 }
 
-func resourceDCNMNetworkImporter(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+func resourceDCNMNetworkImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
 	log.Println("[DEBUG] Begining Importer ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 	importInfo := strings.Split(d.Id(), ":")
 	if len(importInfo) != 2 {
 		return nil, fmt.Errorf("not getting enough arguments for the import operation")
@@ -437,23 +444,23 @@ func resourceDCNMNetworkImporter(d *schema.ResourceData, m interface{}) ([]*sche
 	return []*schema.ResourceData{stateImport}, nil
 }
 
-func resourceDCNMNetworkCreate(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMNetworkCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Create method ")
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	name := d.Get("name").(string)
 	fabricName := d.Get("fabric_name").(string)
 
 	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
 		if _, ok := d.GetOk("attachments"); !ok {
-			return fmt.Errorf("attachments must be configured if deploy=true")
+			return diag.Errorf("attachments must be configured if deploy=true")
 		}
 	}
 
 	cont, err := dcnmClient.GetSegID(fmt.Sprintf("/rest/managed-pool/fabrics/%s/segments/ids", fabricName))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	segID := cont.S("segmentId").String()
 
@@ -496,7 +503,7 @@ func resourceDCNMNetworkCreate(d *schema.ResourceData, m interface{}) error {
 		durl := fmt.Sprintf("/rest/resource-manager/vlan/%s?vlanUsageType=TOP_DOWN_NETWORK_VLAN", fabricName)
 		cont, err := dcnmClient.GetviaURL(durl)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		vlan, err := strconv.Atoi(cont.String())
 		if err == nil {
@@ -556,101 +563,58 @@ func resourceDCNMNetworkCreate(d *schema.ResourceData, m interface{}) error {
 
 	configStr, err := json.Marshal(networkProfile)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	network.Config = string(configStr)
 
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks", fabricName)
 	_, err = dcnmClient.Save(durl, &network)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(name)
 
-	//Network Deployment
-	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
-		if _, ok := d.GetOk("attachments"); ok {
-			attachList := make([]map[string]interface{}, 0, 1)
-			for _, val := range d.Get("attachments").(*schema.Set).List() {
-				attachment := val.(map[string]interface{})
-
-				attachMap := make(map[string]interface{})
-
-				attachMap["fabric"] = network.Fabric
-				attachMap["networkName"] = network.Name
-				attachMap["deployment"] = attachment["attach"].(bool)
-				attachMap["serialNumber"] = attachment["serial_number"].(string)
-
-				if attachment["vlan_id"].(int) != 0 {
-					attachMap["vlan"] = attachment["vlan_id"].(int)
-				} else {
-					attachMap["vlan"] = networkProfile.Vlan
-				}
-				if attachment["switch_ports"] != nil {
-					attachMap["switchPorts"] = listToString(attachment["switch_ports"])
-				}
-
-				if attachment["dot1_qvlan"] != nil {
-					attachMap["dot1QVlan"] = attachment["dot1_qvlan"].(int)
-				}
-
-				if attachment["untagged"] != nil {
-					attachMap["untagged"] = attachment["untagged"].(bool)
-				}
-
-				if attachment["free_form_config"] != nil {
-					attachMap["freeformConfig"] = attachment["free_form_config"].(string)
-				}
-
-				if attachment["extension_values"] != nil {
-					attachMap["extensionValues"] = attachment["extension_values"].(string)
-				}
-
-				if attachment["instanceValues"] != nil {
-					attachMap["instanceValues"] = attachment["instance_values"].(string)
-				}
+	//Network Attachment
+	if _, ok := d.GetOk("attachments"); ok {
+		attachList := make([]map[string]interface{}, 0, 1)
+		for _, val := range d.Get("attachments").(*schema.Set).List() {
+			attachment := val.(map[string]interface{})
+			attachMap := getNetworkAttachPayload(network.Fabric, network.Name, networkProfile.Vlan, attachment, attachment["switch_ports"].([]interface{}), nil)
+			attachList = append(attachList, attachMap)
+		}
 
-				attachList = append(attachList, attachMap)
-			}
+		err := attachNetwork(dcnmClient, network.Fabric, network.Name, attachList)
+		if err != nil {
+			d.Set("deploy", false)
+			d.Set("attachments", make([]interface{}, 0, 1))
+			return append(resourceDCNMNetworkRead(ctx, d, m), deployWarning("Network record is created but not deployed yet", fmt.Errorf("Error while attachment : %s", err)))
+		}
 
-			networkAttach := models.NewNetworkAttachment(network.Name, attachList)
-			durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/attachments", network.Fabric)
-			cont, err := dcnmClient.SaveForAttachment(durl, networkAttach)
+		// Network Deployment, with deploy=false it is left to dcnm_deployment
+		if d.Get("deploy").(bool) {
+			err = deployNetwork(dcnmClient, network.Fabric, network.Name)
 			if err != nil {
 				d.Set("deploy", false)
-				d.Set("attachments", make([]interface{}, 0, 1))
-				return fmt.Errorf("Network record is created but not deployed yet. Error while attachment : %s", err)
+				return append(resourceDCNMNetworkRead(ctx, d, m), deployWarning("Network record is created but not deployed yet", err))
 			}
 
-			// Network Deployment
-			for _, v := range cont.Data().(map[string]interface{}) {
-				if v != "SUCCESS" && v != "SUCCESS Peer attach Reponse :  SUCCESS" {
-					return fmt.Errorf("Network record is created but not deployed yet. Error while attachment : %s", v)
-				}
-			}
-
-			durl = fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s/deploy", network.Fabric, network.Name)
-			_, err = dcnmClient.SaveAndDeploy(durl)
+			err = waitForDeployment(ctx, d.Timeout(schema.TimeoutCreate), func() (bool, error) {
+				return checkNetworkDeployDone(dcnmClient, network.Fabric, network.Name)
+			})
 			if err != nil {
-				d.Set("deploy", false)
+				return diag.FromErr(err)
 			}
-			time.Sleep(10 * time.Second)
-
-		} else {
-			d.Set("deploy", false)
-			d.Set("attachments", make([]interface{}, 0, 1))
-			return fmt.Errorf("Network record is created but not deployed yet. Either make deploy=false or provide attachments")
 		}
 	}
 
 	log.Println("[DEBUG] End of Create method ", d.Id())
-	return resourceDCNMNetworkRead(d, m)
+	return resourceDCNMNetworkRead(ctx, d, m)
 }
 
-func resourceDCNMNetworkUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Update method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	name := d.Get("name").(string)
 	fabricName := d.Get("fabric_name").(string)
@@ -658,7 +622,7 @@ func resourceDCNMNetworkUpdate(d *schema.ResourceData, m interface{}) error {
 
 	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
 		if _, ok := d.GetOk("attachments"); !ok {
-			return fmt.Errorf("attachments must be configured if deploy=true")
+			return diag.Errorf("attachments must be configured if deploy=true")
 		}
 	}
 
@@ -751,7 +715,7 @@ func resourceDCNMNetworkUpdate(d *schema.ResourceData, m interface{}) error {
 
 	configStr, err := json.Marshal(networkProfile)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	network.Config = string(configStr)
 
@@ -759,134 +723,90 @@ func resourceDCNMNetworkUpdate(d *schema.ResourceData, m interface{}) error {
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s", fabricName, dn)
 	_, err = dcnmClient.Update(durl, &network)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(name)
 
-	//Network Deployment
-	if d.HasChange("deploy") && d.Get("deploy").(bool) == false {
-		return fmt.Errorf("Deployed network can not be undeployed")
-	}
-
-	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
-		if _, ok := d.GetOk("attachments"); ok {
-			attachList := make([]map[string]interface{}, 0, 1)
-			for _, val := range d.Get("attachments").(*schema.Set).List() {
-				attachment := val.(map[string]interface{})
-
-				attachMap := make(map[string]interface{})
-
-				attachMap["fabric"] = network.Fabric
-				attachMap["networkName"] = network.Name
-				attachMap["deployment"] = attachment["attach"].(bool)
-				attachMap["serialNumber"] = attachment["serial_number"].(string)
-
-				if attachment["vlan_id"].(int) != 0 {
-					attachMap["vlan"] = attachment["vlan_id"].(int)
-				} else {
-					attachMap["vlan"] = networkProfile.Vlan
-				}
-
-				oldAttachments, newAttachments := d.GetChange("attachments")
-				sPorts, dsPorts := findDiffForPorts(oldAttachments.(*schema.Set).List(), newAttachments.(*schema.Set).List(), attachMap["serialNumber"].(string))
-				if len(sPorts.([]interface{})) > 0 {
-					attachMap["switchPorts"] = listToString(sPorts)
-				} else {
-					attachMap["switchPorts"] = ""
-				}
-
-				if len(dsPorts.([]interface{})) > 0 {
-					attachMap["detachSwitchPorts"] = listToString(dsPorts)
-				} else {
-					attachMap["detachSwitchPorts"] = ""
-				}
-
-				if attachment["dot1_qvlan"] != nil {
-					attachMap["dot1QVlan"] = attachment["dot1_qvlan"].(int)
-				}
-
-				if attachment["untagged"] != nil {
-					attachMap["untagged"] = attachment["untagged"].(bool)
-				}
-
-				if attachment["free_form_config"] != nil {
-					attachMap["freeformConfig"] = attachment["free_form_config"].(string)
-				}
-
-				if attachment["extension_values"] != nil {
-					attachMap["extensionValues"] = attachment["extension_values"].(string)
-				}
+	//Network Attachment, unchanged attachments are only sent again to be deployed
+	if _, ok := d.GetOk("attachments"); ok && (d.HasChange("attachments") || d.Get("deploy").(bool)) {
+		oldAttachments, newAttachments := d.GetChange("attachments")
 
-				if attachment["instanceValues"] != nil {
-					attachMap["instanceValues"] = attachment["instance_values"].(string)
-				}
+		attachList := make([]map[string]interface{}, 0, 1)
+		for _, val := range d.Get("attachments").(*schema.Set).List() {
+			attachment := val.(map[string]interface{})
+			sPorts, dsPorts := findDiffForPorts(oldAttachments.(*schema.Set).List(), newAttachments.(*schema.Set).List(), attachment["serial_number"].(string))
+			attachMap := getNetworkAttachPayload(network.Fabric, network.Name, networkProfile.Vlan, attachment, sPorts.([]interface{}), dsPorts.([]interface{}))
+			attachList = append(attachList, attachMap)
+		}
 
-				attachList = append(attachList, attachMap)
-			}
+		err := attachNetwork(dcnmClient, network.Fabric, network.Name, attachList)
+		if err != nil {
+			d.Set("deploy", false)
+			d.Set("attachments", make([]interface{}, 0, 1))
+			return append(resourceDCNMNetworkRead(ctx, d, m), deployWarning("Network record is updated but not deployed yet", fmt.Errorf("Error while attachment : %s", err)))
+		}
 
-			networkAttach := models.NewNetworkAttachment(network.Name, attachList)
-			durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/attachments", network.Fabric)
-			cont, err := dcnmClient.SaveForAttachment(durl, networkAttach)
+		// Network Deployment, with deploy=false it is left to dcnm_deployment
+		if d.Get("deploy").(bool) {
+			err = deployNetwork(dcnmClient, network.Fabric, network.Name)
 			if err != nil {
 				d.Set("deploy", false)
-				d.Set("attachments", make([]interface{}, 0, 1))
-				return fmt.Errorf("Network record is updated but not deployed yet. Error while attachment : %s", err)
+				return append(resourceDCNMNetworkRead(ctx, d, m), deployWarning("Network record is updated but not deployed yet", err))
 			}
 
-			// Network Deployment
-			for _, v := range cont.Data().(map[string]interface{}) {
-				if v != "SUCCESS" && v != "SUCCESS Peer attach Reponse :  SUCCESS" {
-					return fmt.Errorf("Network record is updated but not deployed yet. Error while attachment : %s", v)
-				}
-			}
-
-			durl = fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s/deploy", network.Fabric, network.Name)
-			_, err = dcnmClient.SaveAndDeploy(durl)
+			err = waitForDeployment(ctx, d.Timeout(schema.TimeoutUpdate), func() (bool, error) {
+				return checkNetworkDeployDone(dcnmClient, network.Fabric, network.Name)
+			})
 			if err != nil {
-				d.Set("deploy", false)
+				return diag.FromErr(err)
 			}
-			time.Sleep(10 * time.Second)
-
-		} else {
-			d.Set("deploy", false)
-			d.Set("attachments", make([]interface{}, 0, 1))
-			return fmt.Errorf("Network record is updated but not deployed yet. Either make deploy=false or provide attachments")
 		}
 	}
 
 	log.Println("[DEBUG] End of Update method ", d.Id())
-	return resourceDCNMNetworkRead(d, m)
+	return resourceDCNMNetworkRead(ctx, d, m)
 }
 
-func resourceDCNMNetworkRead(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMNetworkRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Read method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	dn := d.Id()
 	fabricName := d.Get("fabric_name").(string)
 
 	cont, err := getRemoteNetwork(dcnmClient, fabricName, dn)
 	if err != nil {
-		return err
+		if isNotFound(err) {
+			log.Printf("[WARN] Network %s not found, removing from state", dn)
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 
 	setNetworkAttributes(d, cont)
 
-	deployed, err := checkNetworkDeploy(dcnmClient, fabricName, dn)
-	if err != nil {
-		d.Set("deploy", false)
-		return err
-	}
-	d.Set("deploy", deployed)
-
+	// without attachments of its own the switches are attached through
+	// dcnm_network_attachment, which also tracks their deployment.
 	if attaches, ok := d.GetOk("attachments"); ok {
+		// with deploy=false the network is deployed by dcnm_deployment,
+		// its deployment is not tracked here.
+		if d.Get("deploy").(bool) {
+			deployed, err := checkNetworkDeploy(dcnmClient, fabricName, dn)
+			if err != nil {
+				d.Set("deploy", false)
+				return diag.FromErr(err)
+			}
+			d.Set("deploy", deployed)
+		}
+
 		attachGet := make([]interface{}, 0, 1)
 
 		durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s/attachments", fabricName, dn)
 		cont, err := dcnmClient.GetviaURL(durl)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 
 		for _, val := range attaches.(*schema.Set).List() {
@@ -920,66 +840,51 @@ func resourceDCNMNetworkRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceDCNMNetworkDelete(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMNetworkDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Delete method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	dn := d.Id()
 	fabricName := d.Get("fabric_name").(string)
 
-	if d.Get("deploy").(bool) == true {
-		if attachments, ok := d.GetOk("attachments"); ok {
-			attachList := make([]map[string]interface{}, 0, 1)
-			for _, val := range attachments.(*schema.Set).List() {
-				attachment := val.(map[string]interface{})
-
-				attachMap := make(map[string]interface{})
-
-				attachMap["fabric"] = fabricName
-				attachMap["networkName"] = dn
-				attachMap["deployment"] = false
-				attachMap["serialNumber"] = attachment["serial_number"].(string)
-				if attachment["vlan_id"].(int) == 0 {
-					attachMap["vlan"] = d.Get("vlan_id").(int)
-				} else {
-					attachMap["vlan"] = attachment["vlan_id"].(int)
-				}
-				attachMap["detachSwitchPorts"] = ""
-				attachMap["dot1QVlan"] = 0
-				attachMap["extensionValues"] = ""
-				attachMap["untagged"] = false
-				attachMap["switchPorts"] = ""
+	if attachments, ok := d.GetOk("attachments"); ok {
+		attachList := make([]map[string]interface{}, 0, 1)
+		for _, val := range attachments.(*schema.Set).List() {
+			attachment := val.(map[string]interface{})
 
-				attachList = append(attachList, attachMap)
+			vlan := attachment["vlan_id"].(int)
+			if vlan == 0 {
+				vlan = d.Get("vlan_id").(int)
 			}
+			attachList = append(attachList, getNetworkDetachPayload(fabricName, dn, attachment["serial_number"].(string), vlan))
+		}
 
-			networkAttach := models.NewNetworkAttachment(dn, attachList)
-			durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/attachments", fabricName)
-			cont, err := dcnmClient.SaveForAttachment(durl, networkAttach)
-			if err != nil {
-				return err
-			}
+		err := attachNetwork(dcnmClient, fabricName, dn, attachList)
+		if err != nil {
+			return diag.Errorf("Error while detachment : %s", err)
+		}
 
-			// Network Deployment
-			for _, v := range cont.Data().(map[string]interface{}) {
-				if v != "SUCCESS" && v != "SUCCESS Peer attach Reponse :  SUCCESS" {
-					return fmt.Errorf("Error while detachment : %s", v)
-				}
-			}
-			durl = fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s/deploy", fabricName, dn)
-			_, err = dcnmClient.SaveAndDeploy(durl)
+		// Network Deployment
+		if d.Get("deploy").(bool) {
+			err = deployNetwork(dcnmClient, fabricName, dn)
 			if err != nil {
 				d.Set("deploy", false)
+			} else {
+				err = waitForDeployment(ctx, d.Timeout(schema.TimeoutDelete), func() (bool, error) {
+					return checkNetworkDeployDone(dcnmClient, fabricName, dn)
+				})
+				if err != nil {
+					return diag.FromErr(err)
+				}
 			}
-			time.Sleep(10 * time.Second)
 		}
 	}
 
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s", fabricName, dn)
 	_, err := dcnmClient.Delete(durl)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId("")
@@ -988,7 +893,7 @@ func resourceDCNMNetworkDelete(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func checkNetworkDeploy(client *client.Client, fabricName, dn string) (bool, error) {
+func checkNetworkDeploy(client *Client, fabricName, dn string) (bool, error) {
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s/attachments", fabricName, dn)
 	cont, err := client.GetviaURL(durl)
 	if err != nil {
@@ -1005,6 +910,44 @@ func checkNetworkDeploy(client *client.Client, fabricName, dn string) (bool, err
 	return flag, nil
 }
 
+// checkNetworkDeployDone reports whether DCNM has finished deploying every
+// attachment of the network.
+func checkNetworkDeployDone(client *Client, fabricName, dn string) (bool, error) {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s/attachments", fabricName, dn)
+	cont, err := client.GetviaURL(durl)
+	if err != nil {
+		return false, err
+	}
+
+	for i := 0; i < len(cont.Data().([]interface{})); i++ {
+		done, err := checkAttachState(cont.Index(i))
+		if err != nil || !done {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// deployFailedError is returned for attachments which DCNM failed to deploy.
+type deployFailedError string
+
+func (e deployFailedError) Error() string {
+	return string(e)
+}
+
+// checkAttachState reports whether a switch attachment of a VRF or network
+// has left the transient deployment states.
+func checkAttachState(cont *container.Container) (bool, error) {
+	state := stripQuotes(cont.S("lanAttachState").String())
+	switch state {
+	case "PENDING", "IN PROGRESS", "OUT-OF-SYNC":
+		return false, nil
+	case "FAILED":
+		return false, deployFailedError(fmt.Sprintf("deployment failed on switch %s", stripQuotes(cont.S("switchSerialNo").String())))
+	}
+	return true, nil
+}
+
 func getNetworkSwitchAttachStatus(cont *container.Container, serial string) (bool, []string, int, error) {
 	for i := 0; i < len(cont.Data().([]interface{})); i++ {
 		if stripQuotes(cont.Index(i).S("switchSerialNo").String()) == serial {
@@ -1027,6 +970,100 @@ func getNetworkSwitchAttachStatus(cont *container.Container, serial string) (boo
 	return false, nil, 0, nil
 }
 
+// getNetworkAttachPayload builds the lanAttachList entry for a single switch,
+// from an attachment in the form of the attachments schema. Only attachPorts
+// are attached and detachPorts detached, other ports of the switch are kept.
+func getNetworkAttachPayload(fabric, network string, vlan int, attachment map[string]interface{}, attachPorts, detachPorts []interface{}) map[string]interface{} {
+	attachMap := make(map[string]interface{})
+
+	attachMap["fabric"] = fabric
+	attachMap["networkName"] = network
+	attachMap["deployment"] = attachment["attach"].(bool)
+	attachMap["serialNumber"] = attachment["serial_number"].(string)
+
+	if attachment["vlan_id"].(int) != 0 {
+		attachMap["vlan"] = attachment["vlan_id"].(int)
+	} else {
+		attachMap["vlan"] = vlan
+	}
+
+	attachMap["switchPorts"] = listToString(attachPorts)
+	attachMap["detachSwitchPorts"] = listToString(detachPorts)
+
+	if attachment["dot1_qvlan"] != nil {
+		attachMap["dot1QVlan"] = attachment["dot1_qvlan"].(int)
+	}
+	if attachment["untagged"] != nil {
+		attachMap["untagged"] = attachment["untagged"].(bool)
+	}
+	// empty values are sent as well, so settings removed from the
+	// configuration are cleared on the controller.
+	if freeForm, ok := attachment["free_form_config"].(string); ok {
+		attachMap["freeformConfig"] = freeForm
+	}
+	if extValues, ok := attachment["extension_values"].(string); ok {
+		attachMap["extensionValues"] = extValues
+	}
+	if instValues, ok := attachment["instance_values"].(string); ok {
+		attachMap["instanceValues"] = instValues
+	}
+
+	return attachMap
+}
+
+// getNetworkDetachPayload builds the lanAttachList entry removing the switch,
+// along with all of its ports, from the network.
+func getNetworkDetachPayload(fabric, network, serialNum string, vlan int) map[string]interface{} {
+	attachMap := make(map[string]interface{})
+
+	attachMap["fabric"] = fabric
+	attachMap["networkName"] = network
+	attachMap["deployment"] = false
+	attachMap["serialNumber"] = serialNum
+	attachMap["vlan"] = vlan
+	attachMap["detachSwitchPorts"] = ""
+	attachMap["dot1QVlan"] = 0
+	attachMap["extensionValues"] = ""
+	attachMap["untagged"] = false
+	attachMap["switchPorts"] = ""
+
+	return attachMap
+}
+
+// attachNetwork saves the attach list of the network, only the switches
+// listed are attached or detached.
+func attachNetwork(client *Client, fabric, network string, attachList []map[string]interface{}) error {
+	networkAttach := models.NewNetworkAttachment(network, attachList)
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/attachments", fabric)
+	cont, err := client.SaveForAttachment(durl, networkAttach)
+	if err != nil {
+		return err
+	}
+
+	results, _ := cont.Data().(map[string]interface{})
+	for _, v := range results {
+		if v != "SUCCESS" && v != "SUCCESS Peer attach Reponse :  SUCCESS" {
+			return fmt.Errorf("%s", v)
+		}
+	}
+	return nil
+}
+
+// deployNetwork deploys the pending attachments of the network.
+func deployNetwork(client *Client, fabric, network string) error {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s/deploy", fabric, network)
+	_, err := client.SaveAndDeploy(durl)
+	return err
+}
+
+// deployNetworks deploys the pending attachments of all of the networks with
+// a single request.
+func deployNetworks(client *Client, fabric string, networks []string) error {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/deployments", fabric)
+	_, err := client.Save(durl, deployPayload{"networkNames": strings.Join(networks, ",")})
+	return err
+}
+
 func findDiffForPorts(oldAttachments interface{}, newAttachments interface{}, serial string) (interface{}, interface{}) {
 	oldPorts := make([]string, 0, 1)
 	newPorts := make([]string, 0, 1)
@@ -0,0 +1,392 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceDCNMNetworkAttachment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDCNMNetworkAttachmentCreate,
+		ReadContext:   resourceDCNMNetworkAttachmentRead,
+		UpdateContext: resourceDCNMNetworkAttachmentUpdate,
+		DeleteContext: resourceDCNMNetworkAttachmentDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDCNMNetworkAttachmentImporter,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"fabric_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"network_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"serial_number": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"vlan_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+
+			"switch_ports": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"untagged": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"dot1_qvlan": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+
+			"free_form_config": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"extension_values": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"instance_values": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"deploy": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+	}
+}
+
+// getRemoteNetworkAttachment returns the attachment of the switch, if it is
+// attached to the network.
+func getRemoteNetworkAttachment(client *Client, fabric, network, serialNum string) (*container.Container, error) {
+	attach, err := getNetworkSwitchAttachment(client, fabric, network, serialNum)
+	if err != nil {
+		return nil, err
+	}
+	if stripQuotes(attach.S("isLanAttached").String()) != "true" {
+		return nil, notFoundError(fmt.Sprintf("Network %s is not attached to switch %s", network, serialNum))
+	}
+	return attach, nil
+}
+
+// getNetworkSwitchAttachment returns the attachment of the switch, whether it
+// is attached or not.
+func getNetworkSwitchAttachment(client *Client, fabric, network, serialNum string) (*container.Container, error) {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s/attachments", fabric, network)
+	cont, err := client.GetviaURL(durl)
+	if err != nil {
+		return nil, err
+	}
+
+	attaches, _ := cont.Data().([]interface{})
+	for i := 0; i < len(attaches); i++ {
+		attach := cont.Index(i)
+		if stripQuotes(attach.S("switchSerialNo").String()) == serialNum {
+			return attach, nil
+		}
+	}
+	return nil, notFoundError(fmt.Sprintf("Network %s is not attached to switch %s", network, serialNum))
+}
+
+func setNetworkAttachmentAttributes(d *schema.ResourceData, cont *container.Container) *schema.ResourceData {
+	if vlan, err := strconv.Atoi(stripQuotes(cont.S("vlanId").String())); err == nil {
+		d.Set("vlan_id", vlan)
+	}
+
+	if ports := stripQuotes(cont.S("portNames").String()); ports != "null" && ports != "" {
+		d.Set("switch_ports", stringToList(ports))
+	} else {
+		d.Set("switch_ports", make([]string, 0, 1))
+	}
+
+	if cont.Exists("dot1QVlan") {
+		if dot1Q, err := strconv.Atoi(stripQuotes(cont.S("dot1QVlan").String())); err == nil {
+			d.Set("dot1_qvlan", dot1Q)
+		}
+	}
+	if cont.Exists("untagged") {
+		if untagged, err := strconv.ParseBool(stripQuotes(cont.S("untagged").String())); err == nil {
+			d.Set("untagged", untagged)
+		}
+	}
+	if cont.Exists("freeformConfig") {
+		d.Set("free_form_config", stripQuotes(cont.S("freeformConfig").String()))
+	}
+	if cont.Exists("extensionValues") {
+		d.Set("extension_values", stripQuotes(cont.S("extensionValues").String()))
+	}
+	if cont.Exists("instanceValues") {
+		d.Set("instance_values", stripQuotes(cont.S("instanceValues").String()))
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s:%s", d.Get("fabric_name").(string), d.Get("network_name").(string), d.Get("serial_number").(string)))
+	return d
+}
+
+// getNetworkAttachmentConfig returns the configured attachment in the form of
+// the attachments schema of dcnm_network, so the payload can be built the
+// same way.
+func getNetworkAttachmentConfig(d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		"serial_number":    d.Get("serial_number").(string),
+		"vlan_id":          d.Get("vlan_id").(int),
+		"attach":           true,
+		"dot1_qvlan":       d.Get("dot1_qvlan").(int),
+		"untagged":         d.Get("untagged").(bool),
+		"free_form_config": d.Get("free_form_config").(string),
+		"extension_values": d.Get("extension_values").(string),
+		"instance_values":  d.Get("instance_values").(string),
+	}
+}
+
+// getNetworkVlan returns the vlan of the network, used by switches without
+// their own vlan_id.
+func getNetworkVlan(client *Client, fabric, network string) (int, error) {
+	cont, err := getRemoteNetwork(client, fabric, network)
+	if err != nil {
+		return 0, err
+	}
+
+	vlan := 0
+	if config, err := cleanJsonString(stripQuotes(cont.S("networkTemplateConfig").String())); err == nil {
+		vlan, _ = strconv.Atoi(stripQuotes(config.S("vlanId").String()))
+	}
+	return vlan, nil
+}
+
+// deployNetworkAttachment deploys the attachment when deploy is set. Failures
+// to start the deployment are returned as a warning, as the attachment itself
+// is already saved.
+func deployNetworkAttachment(ctx context.Context, d *schema.ResourceData, m interface{}, summary string, timeout time.Duration) diag.Diagnostics {
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	if !d.Get("deploy").(bool) {
+		return nil
+	}
+
+	fabricName := d.Get("fabric_name").(string)
+	networkName := d.Get("network_name").(string)
+	serialNum := d.Get("serial_number").(string)
+
+	err := deployNetworkSwitch(dcnmClient, fabricName, networkName, serialNum)
+	if err != nil {
+		d.Set("deploy", false)
+		return diag.Diagnostics{deployWarning(summary, err)}
+	}
+
+	err = waitForDeployment(ctx, timeout, func() (bool, error) {
+		return checkNetworkAttachmentDeployDone(dcnmClient, fabricName, networkName, serialNum)
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// deployNetworkSwitch deploys the network on the switch only, the pending
+// attachments of the other switches are left as they are.
+func deployNetworkSwitch(client *Client, fabric, network, serialNum string) error {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/networks/deploy", fabric)
+	_, err := client.Save(durl, deployPayload{serialNum: network})
+	return err
+}
+
+// checkNetworkAttachmentDeployDone reports whether DCNM has finished
+// deploying the attachment of the switch.
+func checkNetworkAttachmentDeployDone(client *Client, fabric, network, serialNum string) (bool, error) {
+	attach, err := getNetworkSwitchAttachment(client, fabric, network, serialNum)
+	if err != nil {
+		if isNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return checkAttachState(attach)
+}
+
+func resourceDCNMNetworkAttachmentImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	log.Println("[DEBUG] Begining Importer ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	importInfo := strings.Split(d.Id(), ":")
+	if len(importInfo) != 3 {
+		return nil, fmt.Errorf("not getting enough arguments for the import operation")
+	}
+
+	cont, err := getRemoteNetworkAttachment(dcnmClient, importInfo[0], importInfo[1], importInfo[2])
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("fabric_name", importInfo[0])
+	d.Set("network_name", importInfo[1])
+	d.Set("serial_number", importInfo[2])
+	d.Set("deploy", stripQuotes(cont.S("lanAttachState").String()) == "DEPLOYED")
+	stateImport := setNetworkAttachmentAttributes(d, cont)
+
+	log.Println("[DEBUG] End of Importer ", d.Id())
+	return []*schema.ResourceData{stateImport}, nil
+}
+
+func resourceDCNMNetworkAttachmentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Create method ")
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+	networkName := d.Get("network_name").(string)
+
+	vlan, err := getNetworkVlan(dcnmClient, fabricName, networkName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ports := d.Get("switch_ports").(*schema.Set).List()
+	attachMap := getNetworkAttachPayload(fabricName, networkName, vlan, getNetworkAttachmentConfig(d), ports, nil)
+
+	err = attachNetwork(dcnmClient, fabricName, networkName, []map[string]interface{}{attachMap})
+	if err != nil {
+		return diag.Errorf("Error while attachment : %s", err)
+	}
+	d.SetId(fmt.Sprintf("%s:%s:%s", fabricName, networkName, d.Get("serial_number").(string)))
+
+	diags := deployNetworkAttachment(ctx, d, m, "Network attachment is created but not deployed yet", d.Timeout(schema.TimeoutCreate))
+	if diags.HasError() {
+		return diags
+	}
+
+	log.Println("[DEBUG] End of Create method ", d.Id())
+	return append(resourceDCNMNetworkAttachmentRead(ctx, d, m), diags...)
+}
+
+func resourceDCNMNetworkAttachmentUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Update method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+	networkName := d.Get("network_name").(string)
+
+	vlan, err := getNetworkVlan(dcnmClient, fabricName, networkName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// only the ports added or removed are sent, so DCNM does not touch the
+	// ports which stay attached.
+	oldPorts, newPorts := d.GetChange("switch_ports")
+	detachPorts := oldPorts.(*schema.Set).Difference(newPorts.(*schema.Set)).List()
+	sPorts := newPorts.(*schema.Set).Difference(oldPorts.(*schema.Set)).List()
+	attachMap := getNetworkAttachPayload(fabricName, networkName, vlan, getNetworkAttachmentConfig(d), sPorts, detachPorts)
+
+	err = attachNetwork(dcnmClient, fabricName, networkName, []map[string]interface{}{attachMap})
+	if err != nil {
+		return diag.Errorf("Error while attachment : %s", err)
+	}
+
+	diags := deployNetworkAttachment(ctx, d, m, "Network attachment is updated but not deployed yet", d.Timeout(schema.TimeoutUpdate))
+	if diags.HasError() {
+		return diags
+	}
+
+	log.Println("[DEBUG] End of Update method ", d.Id())
+	return append(resourceDCNMNetworkAttachmentRead(ctx, d, m), diags...)
+}
+
+func resourceDCNMNetworkAttachmentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	cont, err := getRemoteNetworkAttachment(dcnmClient, d.Get("fabric_name").(string), d.Get("network_name").(string), d.Get("serial_number").(string))
+	if err != nil {
+		if isNotFound(err) {
+			log.Printf("[WARN] Network attachment %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	setNetworkAttachmentAttributes(d, cont)
+
+	// drift is only reported for attachments expected to be deployed, a
+	// later deploy of the network must not show a diff for the others.
+	if d.Get("deploy").(bool) {
+		d.Set("deploy", stripQuotes(cont.S("lanAttachState").String()) == "DEPLOYED")
+	}
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
+
+func resourceDCNMNetworkAttachmentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Delete method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+	networkName := d.Get("network_name").(string)
+
+	attachMap := getNetworkDetachPayload(fabricName, networkName, d.Get("serial_number").(string), d.Get("vlan_id").(int))
+	err := attachNetwork(dcnmClient, fabricName, networkName, []map[string]interface{}{attachMap})
+	if err != nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("Error while detachment : %s", err)
+	}
+
+	diags := deployNetworkAttachment(ctx, d, m, "Network attachment is removed but not undeployed yet", d.Timeout(schema.TimeoutDelete))
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId("")
+	log.Println("[DEBUG] End of Delete method ")
+	return diags
+}
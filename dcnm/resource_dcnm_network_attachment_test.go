@@ -0,0 +1,207 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+var providerNetworkAttach *schema.Provider
+
+func TestAccDCNMNetworkAttachment_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactoriesInternal(&providerNetworkAttach),
+		CheckDestroy:      testAccCheckDCNMNetworkAttachmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckDCNMNetworkAttachmentConfig_basic(`"Ethernet1/5"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDCNMNetworkAttachmentExists("dcnm_network_attachment.test"),
+					resource.TestCheckResourceAttr("dcnm_network_attachment.test", "switch_ports.#", "1"),
+				),
+			},
+			{
+				Config: testAccCheckDCNMNetworkAttachmentConfig_basic(`"Ethernet1/5", "Ethernet1/6"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDCNMNetworkAttachmentExists("dcnm_network_attachment.test"),
+					resource.TestCheckResourceAttr("dcnm_network_attachment.test", "switch_ports.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDCNMNetworkAttachmentConfig_basic(ports string) string {
+	return fmt.Sprintf(`
+	resource "dcnm_network" "test" {
+		fabric_name = "fab2"
+		name        = "tf_net_attach"
+		vlan_id     = 2300
+		deploy      = false
+	}
+
+	resource "dcnm_network_attachment" "test" {
+		fabric_name   = dcnm_network.test.fabric_name
+		network_name  = dcnm_network.test.name
+		serial_number = "9ZGMF8CBZK5"
+		switch_ports  = [%s]
+	}
+	`, ports)
+}
+
+func testAccCheckDCNMNetworkAttachmentExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+
+		if !ok {
+			return fmt.Errorf("Network attachment %s not found", name)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No Network attachment dn was set")
+		}
+
+		dcnmClient := (*providerNetworkAttach).Meta().(*Client)
+
+		attrs := rs.Primary.Attributes
+		_, err := getRemoteNetworkAttachment(dcnmClient, attrs["fabric_name"], attrs["network_name"], attrs["serial_number"])
+		return err
+	}
+}
+
+func testAccCheckDCNMNetworkAttachmentDestroy(s *terraform.State) error {
+	dcnmClient := (*providerNetworkAttach).Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type == "dcnm_network_attachment" {
+			attrs := rs.Primary.Attributes
+			_, err := getRemoteNetworkAttachment(dcnmClient, attrs["fabric_name"], attrs["network_name"], attrs["serial_number"])
+			if err == nil {
+				return fmt.Errorf("Network attachment still exists")
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestDCNMNetworkAttachment_MockCRUD(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	rNet := resourceDCNMNetwork()
+	r := resourceDCNMNetworkAttachment()
+
+	rawNet := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_net_attach",
+		"vlan_id":     2210,
+		"deploy":      false,
+	}
+	netState := testMockApply(t, rNet, nil, rawNet, dcnmClient)
+
+	serial1 := testMockSerial(t, dcnmClient, "leaf1")
+	serial2 := testMockSerial(t, dcnmClient, "leaf2")
+
+	raw1 := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"network_name":  "mock_net_attach",
+		"serial_number": serial1,
+		"switch_ports":  []interface{}{"Ethernet1/1", "Ethernet1/2"},
+	}
+	raw2 := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"network_name":  "mock_net_attach",
+		"serial_number": serial2,
+		"vlan_id":       2311,
+		"switch_ports":  []interface{}{"Ethernet1/7"},
+		"deploy":        false,
+	}
+
+	state1 := testMockApply(t, r, nil, raw1, dcnmClient)
+	testMockCheckAttr(t, state1, "id", fmt.Sprintf("fab1:mock_net_attach:%s", serial1))
+	testMockCheckAttr(t, state1, "vlan_id", "2210")
+	testMockCheckAttr(t, state1, "switch_ports.#", "2")
+	testMockCheckAttr(t, state1, "deploy", "true")
+	state1 = testMockPlanEmpty(t, r, state1, raw1, dcnmClient)
+
+	state2 := testMockApply(t, r, nil, raw2, dcnmClient)
+	testMockCheckAttr(t, state2, "vlan_id", "2311")
+	state2 = testMockPlanEmpty(t, r, state2, raw2, dcnmClient)
+
+	// the network has no attachments of its own, so it must not pick up the
+	// deployment done by the attachment resources.
+	testMockPlanEmpty(t, rNet, netState, rawNet, dcnmClient)
+
+	raw1["switch_ports"] = []interface{}{"Ethernet1/2", "Ethernet1/3"}
+	state1 = testMockApply(t, r, state1, raw1, dcnmClient)
+	testMockCheckAttr(t, state1, "switch_ports.#", "2")
+	state1 = testMockPlanEmpty(t, r, state1, raw1, dcnmClient)
+
+	cont, err := getRemoteNetworkAttachment(dcnmClient, "fab1", "mock_net_attach", serial1)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if ports := stripQuotes(cont.S("portNames").String()); ports != "Ethernet1/2,Ethernet1/3" {
+		t.Fatalf("expected ports Ethernet1/2,Ethernet1/3, got : %s", ports)
+	}
+
+	// only the switch of the attachment is deployed.
+	cont, err = getRemoteNetworkAttachment(dcnmClient, "fab1", "mock_net_attach", serial2)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if state := stripQuotes(cont.S("lanAttachState").String()); state == "DEPLOYED" {
+		t.Fatalf("expected Network attachment with deploy false to stay undeployed, got : %s", state)
+	}
+
+	// drift of the attachment settings is reported.
+	testMockServer.outOfBand(func() {
+		attach := testMockServer.netAttach["fab1/mock_net_attach"][serial1]
+		attach["untagged"] = true
+		attach["dot1QVlan"] = 12
+		attach["freeformConfig"] = "interface loopback71"
+		attach["extensionValues"] = "ext"
+		attach["instanceValues"] = "inst"
+	})
+	testMockPlanChanged(t, r, state1, raw1, dcnmClient)
+	state1 = testMockRefresh(t, r, state1, dcnmClient)
+	testMockCheckAttr(t, state1, "untagged", "true")
+	testMockCheckAttr(t, state1, "dot1_qvlan", "12")
+	testMockCheckAttr(t, state1, "free_form_config", "interface loopback71")
+	testMockCheckAttr(t, state1, "extension_values", "ext")
+	testMockCheckAttr(t, state1, "instance_values", "inst")
+	state1 = testMockApply(t, r, state1, raw1, dcnmClient)
+	testMockCheckAttr(t, state1, "untagged", "false")
+	testMockCheckAttr(t, state1, "free_form_config", "")
+	state1 = testMockPlanEmpty(t, r, state1, raw1, dcnmClient)
+
+	imported := testMockImport(t, r, fmt.Sprintf("fab1:mock_net_attach:%s", serial1), dcnmClient)
+	testMockCheckAttr(t, imported, "switch_ports.#", "2")
+	testMockCheckAttr(t, imported, "deploy", "true")
+
+	testMockDestroy(t, r, state1, dcnmClient)
+	if _, err := getRemoteNetworkAttachment(dcnmClient, "fab1", "mock_net_attach", serial1); !isNotFound(err) {
+		t.Fatalf("expected Network attachment to be removed, got : %v", err)
+	}
+	cont, err = getRemoteNetworkAttachment(dcnmClient, "fab1", "mock_net_attach", serial2)
+	if err != nil {
+		t.Fatalf("expected other Network attachment to be kept, got : %s", err)
+	}
+	if ports := stripQuotes(cont.S("portNames").String()); ports != "Ethernet1/7" {
+		t.Fatalf("expected other switch ports to be kept, got : %s", ports)
+	}
+
+	testMockDestroy(t, r, state2, dcnmClient)
+	testMockDestroy(t, rNet, netState, dcnmClient)
+	testMockRefreshGone(t, r, state2, dcnmClient)
+
+	_, err = r.Importer.StateContext(context.Background(), r.Data(&terraform.InstanceState{ID: "fab1:mock_net_attach"}), dcnmClient)
+	if err == nil || !strings.Contains(err.Error(), "not getting enough arguments") {
+		t.Fatalf("expected import id error, got : %v", err)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"strconv"
 	"testing"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/models"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -94,7 +93,7 @@ func testAccCheckDCNMNetworkExists(name string, network *models.Network, profile
 			return fmt.Errorf("No Network dn was set")
 		}
 
-		dcnmClient := (*providerNetwork).Meta().(*client.Client)
+		dcnmClient := (*providerNetwork).Meta().(*Client)
 
 		cont, err := dcnmClient.GetviaURL(fmt.Sprintf("/rest/top-down/fabrics/%s/networks/%s", "fab2", rs.Primary.ID))
 		if err != nil {
@@ -130,7 +129,7 @@ func testAccCheckDCNMNetworkExists(name string, network *models.Network, profile
 }
 
 func testAccCheckDCNMNetworkDestroy(s *terraform.State) error {
-	dcnmClient := (*providerNetwork).Meta().(*client.Client)
+	dcnmClient := (*providerNetwork).Meta().(*Client)
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type == "dcnm_network" {
@@ -172,3 +171,176 @@ func testAccCheckDCNMNetworkAttributes(desc string, network *models.Network, pro
 		return nil
 	}
 }
+
+func TestDCNMNetwork_MockCRUD(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMNetwork()
+
+	raw := map[string]interface{}{
+		"fabric_name":  "fab1",
+		"name":         "mock_net",
+		"vlan_id":      2200,
+		"ipv4_gateway": "192.168.10.1/24",
+		"description":  "first",
+		"deploy":       false,
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "id", "mock_net")
+	testMockCheckAttr(t, state, "vlan_id", "2200")
+	testMockCheckAttr(t, state, "ipv4_gateway", "192.168.10.1/24")
+	testMockCheckAttr(t, state, "deploy", "false")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["description"] = "second"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "description", "second")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	imported := testMockImport(t, r, "fab1:mock_net", dcnmClient)
+	testMockCheckAttr(t, imported, "fabric_name", "fab1")
+	testMockCheckAttr(t, imported, "description", "second")
+
+	testMockDestroy(t, r, state, dcnmClient)
+	if _, err := getRemoteNetwork(dcnmClient, "fab1", "mock_net"); err == nil {
+		t.Fatalf("Network still exists")
+	}
+}
+
+func TestDCNMNetwork_MockDeploy(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMNetwork()
+
+	serial := testMockSerial(t, dcnmClient, "leaf1")
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_net_deploy",
+		"vlan_id":     2201,
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"serial_number": serial,
+				"vlan_id":       2201,
+				"switch_ports":  []interface{}{"Ethernet1/1"},
+			},
+		},
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "deploy", "true")
+	testMockCheckAttr(t, state, "attachments.#", "1")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["attachments"].([]interface{})[0].(map[string]interface{})["switch_ports"] = []interface{}{"Ethernet1/1", "Ethernet1/2"}
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	testMockDestroy(t, r, state, dcnmClient)
+	if _, err := getRemoteNetwork(dcnmClient, "fab1", "mock_net_deploy"); err == nil {
+		t.Fatalf("Network still exists")
+	}
+}
+
+func TestDCNMNetwork_MockAttachWithoutDeploy(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMNetwork()
+
+	serial := testMockSerial(t, dcnmClient, "leaf1")
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_net_nodeploy",
+		"vlan_id":     2202,
+		"deploy":      false,
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"serial_number": serial,
+				"vlan_id":       2202,
+				"switch_ports":  []interface{}{"Ethernet1/1"},
+			},
+		},
+	}
+
+	// the switches are attached, the deployment is left to dcnm_deployment.
+	deploys := testMockServer.callCount("POST", "/rest/top-down/fabrics/fab1/networks/mock_net_nodeploy/deploy")
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	if got := testMockServer.callCount("POST", "/rest/top-down/fabrics/fab1/networks/mock_net_nodeploy/deploy") - deploys; got != 0 {
+		t.Fatalf("expected no deployment request, got %d", got)
+	}
+	attach, err := getRemoteNetworkAttachment(dcnmClient, "fab1", "mock_net_nodeploy", serial)
+	if err != nil {
+		t.Fatalf("expected switch to be attached, got : %s", err)
+	}
+	if lanState := stripQuotes(attach.S("lanAttachState").String()); lanState == "DEPLOYED" {
+		t.Fatalf("expected attachment not to be deployed, got : %s", lanState)
+	}
+	if ports := stripQuotes(attach.S("portNames").String()); ports != "Ethernet1/1" {
+		t.Fatalf("expected ports Ethernet1/1, got : %s", ports)
+	}
+	testMockCheckAttr(t, state, "deploy", "false")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["deploy"] = true
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "deploy", "true")
+
+	// turning deploy off hands the deployment over, it does not undeploy.
+	raw["deploy"] = false
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	attach, err = getRemoteNetworkAttachment(dcnmClient, "fab1", "mock_net_nodeploy", serial)
+	if err != nil {
+		t.Fatalf("expected switch to stay attached, got : %s", err)
+	}
+	if lanState := stripQuotes(attach.S("lanAttachState").String()); lanState != "DEPLOYED" {
+		t.Fatalf("expected attachment to stay deployed, got : %s", lanState)
+	}
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	testMockDestroy(t, r, state, dcnmClient)
+}
+
+func TestDCNMNetwork_MockDeletedOutOfBand(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMNetwork()
+
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_net_gone",
+		"vlan_id":     2203,
+		"deploy":      false,
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	if _, err := dcnmClient.Delete("/rest/top-down/fabrics/fab1/networks/mock_net_gone"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	testMockRefreshGone(t, r, state, dcnmClient)
+}
+
+func TestDCNMNetwork_MockListDataSource(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMNetwork()
+
+	states := make([]*terraform.InstanceState, 0, 2)
+	for i, name := range []string{"mock_list_net1", "mock_list_net2"} {
+		raw := map[string]interface{}{
+			"fabric_name":  "fab1",
+			"name":         name,
+			"vlan_id":      2210 + i,
+			"ipv4_gateway": fmt.Sprintf("192.168.%d.1/24", 110+i),
+			"deploy":       false,
+		}
+		states = append(states, testMockApply(t, r, nil, raw, dcnmClient))
+	}
+
+	data := testMockReadData(t, datasourceDCNMNetworks(), map[string]interface{}{
+		"fabric_name": "fab1",
+		"name_regex":  "^mock_list_net",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "networks.#", "2")
+	testMockCheckAttr(t, data, "networks.0.name", "mock_list_net1")
+	testMockCheckAttr(t, data, "networks.0.vlan_id", "2210")
+	testMockCheckAttr(t, data, "networks.1.ipv4_gateway", "192.168.111.1/24")
+
+	for _, state := range states {
+		testMockDestroy(t, r, state, dcnmClient)
+	}
+}
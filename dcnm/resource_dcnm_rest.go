@@ -1,123 +1,476 @@
 package dcnm
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"reflect"
+	"strings"
+	"time"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+var restMethods = []string{
+	"GET",
+	"PUT",
+	"POST",
+	"DELETE",
+}
+
 func resourceDCNMRest() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceDCNMRestCreate,
-		Update: resourceDCNMRestUpdate,
-		Read:   resourceDCNMRestRead,
-		Delete: resourceDCNMRestDelete,
+		CreateContext: resourceDCNMRestCreate,
+		UpdateContext: resourceDCNMRestUpdate,
+		ReadContext:   resourceDCNMRestRead,
+		DeleteContext: resourceDCNMRestDelete,
+
+		CustomizeDiff: resourceDCNMRestCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"path": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				AtLeastOneOf: []string{"path", "create_path"},
 			},
 
 			"method": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(restMethods, false),
+			},
+
+			"payload": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+
+			"create_path": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				Computed: true,
-				ValidateFunc: validation.StringInSlice([]string{
-					"GET",
-					"PUT",
-					"POST",
-					"DELETE",
-				}, false),
 			},
 
-			"payload": &schema.Schema{
+			"create_method": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(restMethods, false),
+			},
+
+			"create_payload": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+
+			"update_path": &schema.Schema{
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+			},
+
+			"update_method": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(restMethods, false),
+			},
+
+			"update_payload": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+
+			"delete_path": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"delete_method": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(restMethods, false),
+			},
+
+			"delete_payload": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+
+			"id_path": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(jsonPointerRegex, "must be a JSON pointer, such as \"/id\""),
+			},
+
+			"content_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "application/json",
+			},
+
+			"headers": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"expected_status_codes": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(100, 599),
+				},
+			},
+
+			"wait_for": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"field": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringMatch(jsonPointerRegex, "must be a JSON pointer, such as \"/status\""),
+						},
+
+						"success_values": &schema.Schema{
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+
+						"failure_values": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+
+						"timeout": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      600,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+					},
+				},
+			},
+
+			"read_path": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ignore_keys": &schema.Schema{
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ConflictsWith: []string{"compare_keys"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"compare_keys": &schema.Schema{
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ConflictsWith: []string{"ignore_keys"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"response": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
 			},
 		},
 	}
 }
 
-func resourceDCNMRestCreate(d *schema.ResourceData, m interface{}) error {
-	log.Println("[DEBUG] Begining Create method ")
+// resourceDCNMRestCustomizeDiff makes sure the update and delete operations
+// have a path, as only create falls back to create_path when path is not set.
+func resourceDCNMRestCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, m interface{}) error {
+	if _, ok := diff.GetOk("path"); ok || !diff.NewValueKnown("path") {
+		return nil
+	}
+
+	for _, key := range []string{"update_path", "delete_path"} {
+		if _, ok := diff.GetOk(key); !ok && diff.NewValueKnown(key) {
+			return fmt.Errorf("%s must be set when path is not set", key)
+		}
+	}
+	return nil
+}
 
-	dcnmClient := m.(*client.Client)
+// getRestOperation returns the path, method and payload of the operation,
+// which is one of "create", "update" or "delete". Each falls back to the
+// common path, method and payload, and "{id}" in the paths is replaced with
+// the id of the object.
+func getRestOperation(d *schema.ResourceData, op, defaultMethod string) (string, string, string) {
 	path := d.Get("path").(string)
-	payload := d.Get("payload").(string)
+	if opPath, ok := d.GetOk(op + "_path"); ok {
+		path = opPath.(string)
+	}
+	if op != "create" {
+		path = strings.ReplaceAll(path, "{id}", d.Id())
+	}
 
-	var op string
+	method := defaultMethod
+	if opMethod, ok := d.GetOk(op + "_method"); ok {
+		method = opMethod.(string)
+	} else if commonMethod, ok := d.GetOk("method"); ok {
+		method = commonMethod.(string)
+	}
 
-	if method, ok := d.GetOk("method"); ok {
-		op = method.(string)
-	} else {
-		op = "POST"
+	payload := ""
+	if payloadKey := getRestPayloadKey(d, op); payloadKey != "" {
+		payload = d.Get(payloadKey).(string)
 	}
 
-	_, err := makeAndDoRest(dcnmClient, path, op, payload)
-	if err != nil {
-		return err
+	return path, method, payload
+}
+
+// getRestPayloadKey returns the attribute holding the payload of the
+// operation, or an empty string when it is sent without a body.
+func getRestPayloadKey(d *schema.ResourceData, op string) string {
+	keys := []string{op + "_payload", "payload"}
+	switch op {
+	case "update":
+		keys = append(keys, "create_payload")
+	case "delete":
+		// a separate delete path usually takes no body, the common payload
+		// is only sent to the common path.
+		if _, ok := d.GetOk("delete_path"); ok {
+			keys = keys[:1]
+		}
 	}
 
-	d.SetId(path)
+	for _, key := range keys {
+		if _, ok := d.GetOk(key); ok {
+			return key
+		}
+	}
+	return ""
+}
 
-	log.Println("[DEBUG] End of Create method ", d.Id())
-	return resourceDCNMRestRead(d, m)
+// getRestID returns the id of the object created, found in the response at
+// id_path, or the path of the object.
+func getRestID(d *schema.ResourceData, cont *container.Container, path string) (string, error) {
+	idPath, ok := d.GetOk("id_path")
+	if !ok {
+		return path, nil
+	}
+
+	id, err := filterJSON(cont.Data(), idPath.(string))
+	if err != nil {
+		return "", fmt.Errorf("unable to find the id in the create response : %s", err)
+	}
+	if idStr, ok := id.(string); ok {
+		return idStr, nil
+	}
+
+	idJSON, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+	return string(idJSON), nil
 }
 
-func resourceDCNMRestUpdate(d *schema.ResourceData, m interface{}) error {
-	log.Println("[DEBUG] Begining Update method ", d.Id())
+// waitForRest polls the status path of wait_for until its field has one of
+// the success values. The field not being found yet is polled again, as the
+// controller may not have started the task.
+func waitForRest(ctx context.Context, d *schema.ResourceData, client *Client) error {
+	waitList := d.Get("wait_for").([]interface{})
+	if len(waitList) == 0 || waitList[0] == nil {
+		return nil
+	}
+	wait := waitList[0].(map[string]interface{})
+
+	path := strings.ReplaceAll(wait["path"].(string), "{id}", d.Id())
+	field := wait["field"].(string)
+	success := interfaceToStrList(wait["success_values"].([]interface{}))
+	failure := interfaceToStrList(wait["failure_values"].([]interface{}))
+	timeout := time.Duration(wait["timeout"].(int)) * time.Second
+
+	return waitForDeployment(ctx, timeout, func() (bool, error) {
+		cont, resp, err := client.doRaw("GET", path, nil, getRestHeaders(d))
+		if resp == nil {
+			return false, err
+		}
+		if err := checkerrorsRest(cont, resp, err, []int{http.StatusOK}); err != nil {
+			return false, err
+		}
+
+		value, err := filterJSON(cont.Data(), field)
+		if err != nil {
+			log.Printf("[DEBUG] Status of %s not found yet : %s", path, err)
+			return false, nil
+		}
+
+		status, ok := value.(string)
+		if !ok {
+			statusJSON, _ := json.Marshal(value)
+			status = string(statusJSON)
+		}
+
+		if stringInSlice(status, failure) {
+			return false, fmt.Errorf("%s reported status %s", path, status)
+		}
+		return stringInSlice(status, success), nil
+	})
+}
 
-	dcnmClient := m.(*client.Client)
-	path := d.Get("path").(string)
-	payload := d.Get("payload").(string)
+func resourceDCNMRestCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Create method ")
 
-	var op string
+	dcnmClient := m.(*Client).withContext(ctx)
+	path, op, payload := getRestOperation(d, "create", "POST")
 
-	if method, ok := d.GetOk("method"); ok {
-		op = method.(string)
-	} else {
-		op = "PUT"
+	cont, err := makeAndDoRest(dcnmClient, d, path, op, payload)
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	d.Set("response", restResponse(cont))
 
-	_, err := makeAndDoRest(dcnmClient, path, op, payload)
+	id, err := getRestID(d, cont, path)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
+	d.SetId(id)
 
-	d.SetId(path)
+	if err := waitForRest(ctx, d, dcnmClient); err != nil {
+		return diag.FromErr(err)
+	}
 
-	log.Println("[DEBUG] End of Update method ", d.Id())
-	return resourceDCNMRestRead(d, m)
+	log.Println("[DEBUG] End of Create method ", d.Id())
+	return resourceDCNMRestRead(ctx, d, m)
 }
 
-func resourceDCNMRestRead(d *schema.ResourceData, m interface{}) error {
-	return nil
+func resourceDCNMRestUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Update method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	// changes of the create and delete operations only apply to the next
+	// create or delete.
+	payloadKey := getRestPayloadKey(d, "update")
+	if d.HasChanges("path", "method", "update_path", "update_method") || (payloadKey != "" && d.HasChange(payloadKey)) {
+		path, op, payload := getRestOperation(d, "update", "PUT")
+
+		cont, err := makeAndDoRest(dcnmClient, d, path, op, payload)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set("response", restResponse(cont))
+
+		if _, ok := d.GetOk("id_path"); !ok {
+			d.SetId(path)
+		}
+
+		if err := waitForRest(ctx, d, dcnmClient); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	log.Println("[DEBUG] End of Update method ", d.Id())
+	return resourceDCNMRestRead(ctx, d, m)
 }
 
-func resourceDCNMRestDelete(d *schema.ResourceData, m interface{}) error {
-	log.Println("[DEBUG] Begining Delete method ", d.Id())
+func resourceDCNMRestRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
-	path := d.Get("path").(string)
-	payload := d.Get("payload").(string)
+	dcnmClient := m.(*Client).withContext(ctx)
 
-	var op string
+	// without read_path there is no way to fetch the object back, the state
+	// is kept as it was applied.
+	readPath, ok := d.GetOk("read_path")
+	if !ok {
+		return nil
+	}
+
+	cont, resp, err := dcnmClient.doRaw("GET", strings.ReplaceAll(readPath.(string), "{id}", d.Id()), nil, getRestHeaders(d))
+	if resp == nil {
+		return diag.FromErr(err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		log.Printf("[WARN] Object at %s not found, removing from state", readPath)
+		d.SetId("")
+		return nil
+	}
+	if err := checkerrorsRest(cont, resp, err, []int{http.StatusOK}); err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("response", restResponse(cont))
+
+	// the object is compared with the payload sent on update, so a drift is
+	// fixed by the next apply. Payloads which are not JSON can not be
+	// compared.
+	payloadKey := getRestPayloadKey(d, "update")
+	if payloadKey == "" || cont == nil || !isJSONContentType(d.Get("content_type").(string)) {
+		return nil
+	}
 
-	if method, ok := d.GetOk("method"); ok {
-		op = method.(string)
-	} else {
-		op = "DELETE"
+	var payload interface{}
+	if err := json.Unmarshal([]byte(d.Get(payloadKey).(string)), &payload); err != nil {
+		return diag.FromErr(err)
 	}
 
-	_, err := makeAndDoRest(dcnmClient, path, op, payload)
+	remote := projectJSON(cont.Data(), payload)
+	if keys, ok := d.GetOk("compare_keys"); ok {
+		remote = copyJSON(payload)
+		for _, key := range keys.(*schema.Set).List() {
+			copyJSONKey(remote, cont.Data(), key.(string))
+		}
+	} else if keys, ok := d.GetOk("ignore_keys"); ok {
+		for _, key := range keys.(*schema.Set).List() {
+			copyJSONKey(remote, payload, key.(string))
+		}
+	}
+
+	// the configured payload is kept as long as the controller agrees with
+	// it, so its formatting does not show up as a change.
+	if !reflect.DeepEqual(remote, payload) {
+		drifted, err := json.Marshal(remote)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set(payloadKey, string(drifted))
+	}
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
+
+func resourceDCNMRestDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Delete method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+	path, op, payload := getRestOperation(d, "delete", "DELETE")
+
+	_, err := makeAndDoRest(dcnmClient, d, path, op, payload)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId("")
@@ -126,33 +479,165 @@ func resourceDCNMRestDelete(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func makeAndDoRest(client *client.Client, path, op, payload string) (*container.Container, error) {
-	jsonPayload, err := container.ParseJSON([]byte(payload))
-	if err != nil {
-		return nil, err
+// getRestHeaders returns the headers sent with every request of the object,
+// the configured ones taking precedence over content_type.
+func getRestHeaders(d *schema.ResourceData) map[string]string {
+	headers := map[string]string{
+		"Content-Type": d.Get("content_type").(string),
+	}
+	for key, val := range d.Get("headers").(map[string]interface{}) {
+		headers[key] = val.(string)
 	}
+	return headers
+}
 
-	req, err := client.MakeRequest(op, path, jsonPayload, true)
-	if err != nil {
-		return nil, err
+// getRestStatusCodes returns the status codes of successful responses, which
+// is only 200 unless configured otherwise.
+func getRestStatusCodes(d *schema.ResourceData) []int {
+	codes := make([]int, 0, 1)
+	for _, code := range d.Get("expected_status_codes").([]interface{}) {
+		codes = append(codes, code.(int))
+	}
+	if len(codes) == 0 {
+		codes = append(codes, http.StatusOK)
 	}
+	return codes
+}
 
-	respCont, resp, err := client.Do(req)
-	if err != nil {
-		return nil, checkerrorsRest(respCont, resp)
+func isJSONContentType(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "json")
+}
+
+// restResponse returns the response body, which is empty when DCNM did not
+// answer with JSON.
+func restResponse(cont *container.Container) string {
+	if cont == nil {
+		return ""
+	}
+	return cont.String()
+}
+
+func makeAndDoRest(client *Client, d *schema.ResourceData, path, op, payload string) (*container.Container, error) {
+	var body []byte
+	if payload != "" {
+		// JSON payloads are checked before being sent, the other ones are
+		// sent as they are configured.
+		if isJSONContentType(d.Get("content_type").(string)) && !json.Valid([]byte(payload)) {
+			return nil, fmt.Errorf("payload of %s %s is not valid JSON", op, path)
+		}
+		body = []byte(payload)
+	}
+
+	respCont, resp, err := client.doRaw(op, path, body, getRestHeaders(d))
+	if resp == nil {
+		return nil, err
 	}
 
-	return respCont, checkerrorsRest(respCont, resp)
+	return respCont, checkerrorsRest(respCont, resp, err, getRestStatusCodes(d))
 }
 
-func checkerrorsRest(cont *container.Container, resp *http.Response) error {
-	if resp.StatusCode == http.StatusOK {
-		return nil
+// checkerrorsRest returns an error unless the status of the response is one
+// of the expected ones. Bodies which are not JSON are returned by the go
+// client as err.
+func checkerrorsRest(cont *container.Container, resp *http.Response, err error, expected []int) error {
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return nil
+		}
 	}
 
 	if cont != nil {
 		return fmt.Errorf("%d Error : %s", resp.StatusCode, cont.S("message").String())
 	}
 
+	if err != nil {
+		return fmt.Errorf("%d Error : %s", resp.StatusCode, err)
+	}
+
 	return fmt.Errorf("%d Error : %s", resp.StatusCode, resp.Status)
 }
+
+// suppressEquivalentJSON ignores changes of the payload which only differ in
+// formatting or key order.
+func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
+	var oldJSON, newJSON interface{}
+	if err := json.Unmarshal([]byte(old), &oldJSON); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &newJSON); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(oldJSON, newJSON)
+}
+
+// projectJSON returns remote restricted to the keys of the objects found in
+// shape, as DCNM answers with many more keys than the ones configured.
+func projectJSON(remote, shape interface{}) interface{} {
+	remoteMap, ok := remote.(map[string]interface{})
+	if !ok {
+		return remote
+	}
+	shapeMap, ok := shape.(map[string]interface{})
+	if !ok {
+		return remote
+	}
+
+	projected := make(map[string]interface{})
+	for key, val := range shapeMap {
+		if remoteVal, ok := remoteMap[key]; ok {
+			projected[key] = projectJSON(remoteVal, val)
+		}
+	}
+	return projected
+}
+
+func copyJSON(data interface{}) interface{} {
+	switch val := data.(type) {
+	case map[string]interface{}:
+		dataCopy := make(map[string]interface{})
+		for k, v := range val {
+			dataCopy[k] = copyJSON(v)
+		}
+		return dataCopy
+	case []interface{}:
+		dataCopy := make([]interface{}, 0, len(val))
+		for _, v := range val {
+			dataCopy = append(dataCopy, copyJSON(v))
+		}
+		return dataCopy
+	}
+	return data
+}
+
+// copyJSONKey sets the value found at the dot separated key of src in dst,
+// removing it from dst when src does not have it.
+func copyJSONKey(dst, src interface{}, key string) {
+	path := strings.Split(key, ".")
+
+	for _, k := range path[:len(path)-1] {
+		dstMap, ok := dst.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if _, ok := dstMap[k].(map[string]interface{}); !ok {
+			dstMap[k] = make(map[string]interface{})
+		}
+		dst = dstMap[k]
+
+		srcMap, _ := src.(map[string]interface{})
+		src = srcMap[k]
+	}
+
+	dstMap, ok := dst.(map[string]interface{})
+	if !ok {
+		return
+	}
+	last := path[len(path)-1]
+	if srcMap, ok := src.(map[string]interface{}); ok {
+		if val, ok := srcMap[last]; ok {
+			dstMap[last] = copyJSON(val)
+			return
+		}
+	}
+	delete(dstMap, last)
+}
@@ -0,0 +1,326 @@
+package dcnm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestDCNMRest_Mock(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMRest()
+
+	serial := testMockSerial(t, dcnmClient, "leaf1")
+
+	raw := map[string]interface{}{
+		"path":    "/rest/control/switches/roles",
+		"method":  "POST",
+		"payload": `[{"serialNumber": "` + serial + `", "role": "border"}]`,
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "id", "/rest/control/switches/roles")
+	if role, _ := getSwitchRole(dcnmClient, serial); role != "border" {
+		t.Fatalf("Bad switch role %s", role)
+	}
+
+	raw["payload"] = `[{"serialNumber": "` + serial + `", "role": "leaf"}]`
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	if role, _ := getSwitchRole(dcnmClient, serial); role != "leaf" {
+		t.Fatalf("Bad switch role %s", role)
+	}
+
+	testMockDestroy(t, r, state, dcnmClient)
+}
+
+func TestDCNMRest_MockDrift(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMRest()
+
+	serial := testMockSerial(t, dcnmClient, "leaf2")
+
+	raw := map[string]interface{}{
+		"path":      "/rest/control/switches/roles",
+		"method":    "POST",
+		"read_path": "/rest/control/switches/roles?serialNumber=" + serial,
+		"payload":   `[{"serialNumber": "` + serial + `", "role": "leaf"}]`,
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	if !strings.Contains(state.Attributes["response"], `"role":"leaf"`) {
+		t.Fatalf("Bad response %s", state.Attributes["response"])
+	}
+
+	testMockServer.outOfBand(func() {
+		testMockServer.roles[serial] = "spine"
+	})
+	testMockPlanChanged(t, r, state, raw, dcnmClient)
+
+	state = testMockApply(t, r, testMockRefresh(t, r, state, dcnmClient), raw, dcnmClient)
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	if role, _ := getSwitchRole(dcnmClient, serial); role != "leaf" {
+		t.Fatalf("Bad switch role %s", role)
+	}
+}
+
+func TestDCNMRest_MockDriftKeys(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMRest()
+
+	rawIgnore := map[string]interface{}{
+		"path":        "/rest/top-down/fabrics/fab1/networks",
+		"read_path":   "/rest/top-down/fabrics/fab1/networks/rest_net1",
+		"payload":     `{"fabric": "fab1", "networkName": "rest_net1", "vlan": 2400, "tag": "100"}`,
+		"ignore_keys": []interface{}{"tag"},
+	}
+	rawCompare := map[string]interface{}{
+		"path":         "/rest/top-down/fabrics/fab1/networks",
+		"read_path":    "/rest/top-down/fabrics/fab1/networks/rest_net2",
+		"payload":      `{"fabric": "fab1", "networkName": "rest_net2", "vlan": 2401, "tag": "100"}`,
+		"compare_keys": []interface{}{"vlan"},
+	}
+
+	stateIgnore := testMockApply(t, r, nil, rawIgnore, dcnmClient)
+	stateCompare := testMockApply(t, r, nil, rawCompare, dcnmClient)
+
+	// keys only known to the controller are not compared, nor are the
+	// ignored ones.
+	testMockServer.outOfBand(func() {
+		for _, name := range []string{"fab1/rest_net1", "fab1/rest_net2"} {
+			testMockServer.networks[name]["networkId"] = 30100
+			testMockServer.networks[name]["tag"] = "200"
+		}
+	})
+	stateIgnore = testMockPlanEmpty(t, r, stateIgnore, rawIgnore, dcnmClient)
+	stateCompare = testMockPlanEmpty(t, r, stateCompare, rawCompare, dcnmClient)
+
+	testMockServer.outOfBand(func() {
+		for _, name := range []string{"fab1/rest_net1", "fab1/rest_net2"} {
+			testMockServer.networks[name]["vlan"] = 2500
+		}
+	})
+	testMockPlanChanged(t, r, stateIgnore, rawIgnore, dcnmClient)
+	testMockPlanChanged(t, r, stateCompare, rawCompare, dcnmClient)
+
+	for _, name := range []string{"rest_net1", "rest_net2"} {
+		if _, err := dcnmClient.Delete("/rest/top-down/fabrics/fab1/networks/" + name); err != nil {
+			t.Fatalf("err : %s", err)
+		}
+	}
+	testMockRefreshGone(t, r, stateIgnore, dcnmClient)
+	testMockRefreshGone(t, r, stateCompare, dcnmClient)
+}
+
+func TestDCNMRestDataSource_Mock(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	d := datasourceDCNMRest()
+
+	data := testMockReadData(t, d, map[string]interface{}{
+		"path": "/rest/control/fabrics/fab1",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "id", "/rest/control/fabrics/fab1")
+	testMockCheckAttr(t, data, "flattened.fabricName", "fab1")
+	testMockCheckAttr(t, data, "flattened.nvPairs.BGP_AS", "65000")
+	if !strings.Contains(data.Attributes["response"], `"fabricName":"fab1"`) {
+		t.Fatalf("Bad response %s", data.Attributes["response"])
+	}
+
+	data = testMockReadData(t, d, map[string]interface{}{
+		"path":   "/rest/control/fabrics/fab1/inventory",
+		"filter": "/1/logicalName",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "result", `"leaf2"`)
+	testMockCheckAttr(t, data, "flattened.value", "leaf2")
+
+	data = testMockReadData(t, d, map[string]interface{}{
+		"path":   "/rest/control/fabrics/fab1/inventory",
+		"filter": "/0",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "flattened.logicalName", "leaf1")
+
+	diff, err := d.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"path":   "/rest/control/fabrics/fab1",
+		"filter": "/nvPairs/MISSING",
+	}), dcnmClient)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	_, diags := d.ReadDataApply(context.Background(), diff, dcnmClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, `key "MISSING"`) {
+		t.Fatalf("expected missing key error, got : %v", diags)
+	}
+}
+
+func TestDCNMRest_MockOperations(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMRest()
+
+	raw := map[string]interface{}{
+		"create_path":    "/rest/top-down/fabrics/fab1/networks",
+		"update_path":    "/rest/top-down/fabrics/fab1/networks/{id}",
+		"delete_path":    "/rest/top-down/fabrics/fab1/networks/{id}",
+		"read_path":      "/rest/top-down/fabrics/fab1/networks/{id}",
+		"id_path":        "/networkName",
+		"create_payload": `{"fabric": "fab1", "networkName": "rest_net3", "vlan": 2402}`,
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "id", "rest_net3")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	updates := testMockServer.callCount("PUT", "/rest/top-down/fabrics/fab1/networks/rest_net3")
+
+	// changing the delete operation does not send any request.
+	raw["delete_method"] = "DELETE"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	if got := testMockServer.callCount("PUT", "/rest/top-down/fabrics/fab1/networks/rest_net3") - updates; got != 0 {
+		t.Fatalf("expected no update request, got %d", got)
+	}
+
+	raw["create_payload"] = `{"fabric": "fab1", "networkName": "rest_net3", "vlan": 2403}`
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+	if got := testMockServer.callCount("PUT", "/rest/top-down/fabrics/fab1/networks/rest_net3") - updates; got != 1 {
+		t.Fatalf("expected one update request, got %d", got)
+	}
+
+	cont, err := dcnmClient.GetviaURL("/rest/top-down/fabrics/fab1/networks/rest_net3")
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	if vlan := cont.S("vlan").String(); vlan != "2403" {
+		t.Fatalf("Bad vlan %s", vlan)
+	}
+
+	testMockDestroy(t, r, state, dcnmClient)
+	if _, err := dcnmClient.GetviaURL("/rest/top-down/fabrics/fab1/networks/rest_net3"); !isNotFound(err) {
+		t.Fatalf("expected object to be deleted, got : %v", err)
+	}
+}
+
+func TestDCNMRest_MissingPath(t *testing.T) {
+	r := resourceDCNMRest()
+
+	raw := map[string]interface{}{
+		"create_path":    "/rest/top-down/fabrics/fab1/networks",
+		"update_path":    "/rest/top-down/fabrics/fab1/networks/{id}",
+		"create_payload": `{"fabric": "fab1", "networkName": "rest_net4"}`,
+	}
+
+	_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), nil)
+	if err == nil || !strings.Contains(err.Error(), "delete_path must be set") {
+		t.Fatalf("expected missing delete_path error, got : %v", err)
+	}
+
+	raw["delete_path"] = "/rest/top-down/fabrics/fab1/networks/{id}"
+	if _, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), nil); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+
+	delete(raw, "update_path")
+	_, err = r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), nil)
+	if err == nil || !strings.Contains(err.Error(), "update_path must be set") {
+		t.Fatalf("expected missing update_path error, got : %v", err)
+	}
+}
+
+func TestDCNMRest_MockContentType(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMRest()
+
+	serial := testMockSerial(t, dcnmClient, "leaf1")
+	switchDbID := ""
+	testMockServer.outOfBand(func() {
+		for _, sw := range testMockServer.switches["fab1"] {
+			if sw["serialNumber"] == serial {
+				switchDbID = sw["switchDbID"].(string)
+			}
+		}
+	})
+
+	credPath := "/fm/fmrest/lanConfig/saveSwitchCredentials"
+	raw := map[string]interface{}{
+		"path":         credPath,
+		"method":       "POST",
+		"content_type": "application/x-www-form-urlencoded",
+		"headers": map[string]interface{}{
+			"X-Requested-With": "terraform",
+		},
+		"payload":               "switchIds=" + switchDbID + "&userName=netadmin&password=secret&v3protocol=0",
+		"delete_path":           "/rest/top-down/fabrics/fab1/networks/rest_missing",
+		"expected_status_codes": []interface{}{200, 404},
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "response", "")
+	if user := testMockServer.credentials[switchDbID]; user != "netadmin" {
+		t.Fatalf("Bad credentials user %s", user)
+	}
+	headers := testMockServer.lastHeaders("POST", credPath)
+	if contentType := headers.Get("Content-Type"); contentType != "application/x-www-form-urlencoded" {
+		t.Fatalf("Bad content type %s", contentType)
+	}
+	if requested := headers.Get("X-Requested-With"); requested != "terraform" {
+		t.Fatalf("Bad header %s", requested)
+	}
+
+	// the object to delete is already gone, which 404 is expected for.
+	testMockDestroy(t, r, state, dcnmClient)
+
+	raw["payload"] = "userName=netadmin"
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), dcnmClient)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	_, diags := r.Apply(context.Background(), nil, diff, dcnmClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "400 Error : switchIds is required") {
+		t.Fatalf("expected bad request error, got : %v", diags)
+	}
+}
+
+func TestDCNMRest_MockWaitFor(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMRest()
+
+	defer func(interval time.Duration) { deployPollInterval = interval }(deployPollInterval)
+	deployPollInterval = time.Millisecond
+
+	raw := map[string]interface{}{
+		"path":          "/rest/imagemanagement/rest/imageupgrade/upgrade-image",
+		"method":        "POST",
+		"payload":       `{"polls": 2, "result": "SUCCESS"}`,
+		"id_path":       "/taskId",
+		"delete_path":   "/rest/imagemanagement/rest/imageupgrade/upgrade-status/{id}",
+		"delete_method": "GET",
+		"wait_for": []interface{}{
+			map[string]interface{}{
+				"path":           "/rest/imagemanagement/rest/imageupgrade/upgrade-status/{id}",
+				"field":          "/status/state",
+				"success_values": []interface{}{"SUCCESS"},
+				"failure_values": []interface{}{"FAILED"},
+				"timeout":        10,
+			},
+		},
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	statusPath := "/rest/imagemanagement/rest/imageupgrade/upgrade-status/" + state.ID
+	if got := testMockServer.callCount("GET", statusPath); got != 3 {
+		t.Fatalf("expected 3 status checks, got %d", got)
+	}
+	testMockDestroy(t, r, state, dcnmClient)
+
+	raw["payload"] = `{"polls": 1, "result": "FAILED"}`
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), dcnmClient)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	_, diags := r.Apply(context.Background(), nil, diff, dcnmClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "reported status FAILED") {
+		t.Fatalf("expected failed task error, got : %v", diags)
+	}
+}
@@ -1,6 +1,7 @@
 package dcnm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -8,22 +9,28 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/container"
 	"github.com/ciscoecosystem/dcnm-go-client/models"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceDCNMVRF() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceDCNMVRFCreate,
-		Read:   resourceDCNMVRFRead,
-		Update: resourceDCNMVRFUpdate,
-		Delete: resourceDCNMVRFDelete,
+		CreateContext: resourceDCNMVRFCreate,
+		ReadContext:   resourceDCNMVRFRead,
+		UpdateContext: resourceDCNMVRFUpdate,
+		DeleteContext: resourceDCNMVRFDelete,
 
 		Importer: &schema.ResourceImporter{
-			State: resourceDCNMVRFImporter,
+			StateContext: resourceDCNMVRFImporter,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -276,7 +283,7 @@ func resourceDCNMVRF() *schema.Resource {
 	}
 }
 
-func getRemoteVRF(client *client.Client, fabricName, vrfName string) (*container.Container, error) {
+func getRemoteVRF(client *Client, fabricName, vrfName string) (*container.Container, error) {
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/%s", fabricName, vrfName)
 
 	cont, err := client.GetviaURL(durl)
@@ -376,10 +383,10 @@ func setVRFAttributes(d *schema.ResourceData, cont *container.Container) *schema
 	return d
 }
 
-func resourceDCNMVRFImporter(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+func resourceDCNMVRFImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
 	log.Println("[DEBUG] Begining Importer ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 	importInfo := strings.Split(d.Id(), ":")
 	if len(importInfo) != 2 {
 		return nil, fmt.Errorf("not getting enough arguments for the import operation")
@@ -405,10 +412,10 @@ func resourceDCNMVRFImporter(d *schema.ResourceData, m interface{}) ([]*schema.R
 	return []*schema.ResourceData{stateImport}, nil
 }
 
-func resourceDCNMVRFCreate(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMVRFCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Create method ")
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	vrf := models.VRF{}
 	vrf.Name = d.Get("name").(string)
@@ -418,14 +425,14 @@ func resourceDCNMVRFCreate(d *schema.ResourceData, m interface{}) error {
 
 	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
 		if _, ok := d.GetOk("attachments"); !ok {
-			return fmt.Errorf("attachments must be configured if deploy=true")
+			return diag.Errorf("attachments must be configured if deploy=true")
 		}
 	}
 
 	//request to get the next vrf segment id
 	cont, err := dcnmClient.GetSegID(fmt.Sprintf("/rest/managed-pool/fabrics/%s/partitions/ids", vrf.Fabric))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	vrf.Id = cont.S("partitionSegmentId").String()
 
@@ -444,7 +451,7 @@ func resourceDCNMVRFCreate(d *schema.ResourceData, m interface{}) error {
 		durl := fmt.Sprintf("/rest/resource-manager/vlan/%s?vlanUsageType=TOP_DOWN_VRF_VLAN", d.Get("fabric_name").(string))
 		cont, err := dcnmClient.GetviaURL(durl)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		vlan, err := strconv.Atoi(cont.String())
 		if err == nil {
@@ -510,105 +517,60 @@ func resourceDCNMVRFCreate(d *schema.ResourceData, m interface{}) error {
 
 	confStr, err := json.Marshal(configMap)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	vrf.Config = string(confStr)
 
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs", vrf.Fabric)
 	_, err = dcnmClient.Save(durl, &vrf)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(vrf.Name)
 
 	//VRF attachment
-	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
-		if _, ok := d.GetOk("attachments"); ok {
-			attachList := make([]map[string]interface{}, 0, 1)
-			for _, val := range d.Get("attachments").(*schema.Set).List() {
-				attachment := val.(map[string]interface{})
-
-				attachMap := make(map[string]interface{})
-
-				attachMap["fabric"] = vrf.Fabric
-				attachMap["vrfName"] = vrf.Name
-				attachMap["deployment"] = attachment["attach"].(bool)
-				attachMap["serialNumber"] = attachment["serial_number"].(string)
-
-				if attachment["vlan_id"].(int) != 0 {
-					attachMap["vlan"] = attachment["vlan_id"].(int)
-				} else {
-					attachMap["vlan"] = configMap.Vlan
-				}
-				if attachment["free_form_config"] != nil {
-					attachMap["freeformConfig"] = attachment["free_form_config"].(string)
-				}
-				if attachment["extension_values"] != nil {
-					attachMap["extensionValues"] = attachment["extension_values"].(string)
-				}
-
-				flag := false
-				instance := models.VRFInstance{}
-				if attachment["loopback_id"] != nil {
-					instance.LookbackID = attachment["loopback_id"].(int)
-					flag = true
-				}
-				if attachment["loopback_ipv4"] != nil {
-					instance.LoopbackIpv4 = attachment["loopback_ipv4"].(string)
-					flag = true
-				}
-				if attachment["loopback_ipv6"] != nil {
-					instance.LoopbackIpv6 = attachment["loopback_ipv6"].(string)
-					flag = true
-				}
-				if flag {
-					instStr, err := json.Marshal(instance)
-					if err != nil {
-						return err
-					}
-					attachMap["instanceValues"] = string(instStr)
-				}
-
-				attachList = append(attachList, attachMap)
-			}
-
-			vrfAttach := models.NewVRFAttachment(vrf.Name, attachList)
-			durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/attachments", vrf.Fabric)
-			cont, err := dcnmClient.SaveForAttachment(durl, vrfAttach)
+	if _, ok := d.GetOk("attachments"); ok {
+		attachList := make([]map[string]interface{}, 0, 1)
+		for _, val := range d.Get("attachments").(*schema.Set).List() {
+			attachMap, err := getVRFAttachPayload(vrf.Fabric, vrf.Name, configMap.Vlan, val.(map[string]interface{}))
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
+			attachList = append(attachList, attachMap)
+		}
 
-			// VRF Deployment
-			for _, v := range cont.Data().(map[string]interface{}) {
-				if v != "SUCCESS" && v != "SUCCESS Peer attach Reponse :  SUCCESS" {
-					return fmt.Errorf("VRF record is created but not deployed yet. Error while attachment : %s", v)
-				}
-			}
-			vrfD := models.VRFDeploy{}
-			vrfD.Name = vrf.Name
-			durl = fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/deployments", vrf.Fabric)
-			_, err = dcnmClient.Save(durl, &vrfD)
+		err := attachVRF(dcnmClient, vrf.Fabric, vrf.Name, attachList)
+		if err != nil {
+			d.Set("deploy", false)
+			d.Set("attachments", make([]interface{}, 0, 1))
+			return append(resourceDCNMVRFRead(ctx, d, m), deployWarning("VRF record is created but not deployed yet", fmt.Errorf("Error while attachment : %s", err)))
+		}
+
+		// VRF Deployment, with deploy=false it is left to dcnm_deployment
+		if d.Get("deploy").(bool) {
+			err = deployVRF(dcnmClient, vrf.Fabric, vrf.Name)
 			if err != nil {
 				d.Set("deploy", false)
+				return append(resourceDCNMVRFRead(ctx, d, m), deployWarning("VRF record is created but not deployed yet", err))
 			}
-			time.Sleep(10 * time.Second)
 
-		} else {
-			d.Set("deploy", false)
-			d.Set("attachments", make([]interface{}, 0, 1))
-			return fmt.Errorf("VRF record is created but not deployed yet. Either make deploy=false or provide attachments")
+			err = waitForDeployment(ctx, d.Timeout(schema.TimeoutCreate), func() (bool, error) {
+				return checkVRFDeployDone(dcnmClient, vrf.Fabric, vrf.Name)
+			})
+			if err != nil {
+				return diag.FromErr(err)
+			}
 		}
 	}
 
 	log.Println("[DEBUG] End of Create method ", d.Id())
-	return resourceDCNMVRFRead(d, m)
+	return resourceDCNMVRFRead(ctx, d, m)
 }
 
-func resourceDCNMVRFUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMVRFUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Update method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	vrf := models.VRF{}
 	vrf.Name = d.Get("name").(string)
@@ -619,7 +581,7 @@ func resourceDCNMVRFUpdate(d *schema.ResourceData, m interface{}) error {
 
 	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
 		if _, ok := d.GetOk("attachments"); !ok {
-			return fmt.Errorf("attachments must be configured if deploy=true")
+			return diag.Errorf("attachments must be configured if deploy=true")
 		}
 	}
 
@@ -694,7 +656,7 @@ func resourceDCNMVRFUpdate(d *schema.ResourceData, m interface{}) error {
 
 	confStr, err := json.Marshal(configMap)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	vrf.Config = string(confStr)
 
@@ -702,121 +664,83 @@ func resourceDCNMVRFUpdate(d *schema.ResourceData, m interface{}) error {
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/%s", vrf.Fabric, dn)
 	_, err = dcnmClient.Update(durl, &vrf)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	d.SetId(vrf.Name)
 
-	//VRF Attachment
-	if d.HasChange("deploy") && d.Get("deploy").(bool) == false {
-		return fmt.Errorf("Deployed VRF can not be undeployed")
-	}
-
-	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
-		if _, ok := d.GetOk("attachments"); ok {
-			attachList := make([]map[string]interface{}, 0, 1)
-			for _, val := range d.Get("attachments").(*schema.Set).List() {
-				attachment := val.(map[string]interface{})
-
-				attachMap := make(map[string]interface{})
-
-				attachMap["fabric"] = vrf.Fabric
-				attachMap["vrfName"] = vrf.Name
-				attachMap["deployment"] = attachment["attach"].(bool)
-				attachMap["serialNumber"] = attachment["serial_number"].(string)
-
-				if attachment["vlan_id"].(int) != 0 {
-					attachMap["vlan"] = attachment["vlan_id"].(int)
-				} else {
-					attachMap["vlan"] = configMap.Vlan
-				}
-				if attachment["free_form_config"] != nil {
-					attachMap["freeformConfig"] = attachment["free_form_config"].(string)
-				}
-				if attachment["extension_values"] != nil {
-					attachMap["extensionValues"] = attachment["extension_values"].(string)
-				}
-
-				flag := false
-				instance := models.VRFInstance{}
-				if attachment["loopback_id"] != nil {
-					instance.LookbackID = attachment["loopback_id"].(int)
-					flag = true
-				}
-				if attachment["loopback_ipv4"] != nil {
-					instance.LoopbackIpv4 = attachment["loopback_ipv4"].(string)
-					flag = true
-				}
-				if attachment["loopback_ipv6"] != nil {
-					instance.LoopbackIpv6 = attachment["loopback_ipv6"].(string)
-					flag = true
-				}
-				if flag {
-					instStr, err := json.Marshal(instance)
-					if err != nil {
-						return err
-					}
-					attachMap["instanceValues"] = string(instStr)
-				}
-
-				attachList = append(attachList, attachMap)
-			}
-
-			vrfAttach := models.NewVRFAttachment(vrf.Name, attachList)
-			durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/attachments", vrf.Fabric)
-			cont, err := dcnmClient.SaveForAttachment(durl, vrfAttach)
+	//VRF Attachment, unchanged attachments are only sent again to be deployed
+	if _, ok := d.GetOk("attachments"); ok && (d.HasChange("attachments") || d.Get("deploy").(bool)) {
+		attachList := make([]map[string]interface{}, 0, 1)
+		for _, val := range d.Get("attachments").(*schema.Set).List() {
+			attachMap, err := getVRFAttachPayload(vrf.Fabric, vrf.Name, configMap.Vlan, val.(map[string]interface{}))
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
+			attachList = append(attachList, attachMap)
+		}
 
-			// VRF Deployment
-			for _, v := range cont.Data().(map[string]interface{}) {
-				if v != "SUCCESS" && v != "SUCCESS Peer attach Reponse :  SUCCESS" {
-					return fmt.Errorf("VRF record is created but not deployed yet. Error while attachment : %s", v)
-				}
-			}
-			vrfD := models.VRFDeploy{}
-			vrfD.Name = vrf.Name
-			durl = fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/deployments", vrf.Fabric)
-			_, err = dcnmClient.Save(durl, &vrfD)
+		err := attachVRF(dcnmClient, vrf.Fabric, vrf.Name, attachList)
+		if err != nil {
+			d.Set("deploy", false)
+			d.Set("attachments", make([]interface{}, 0, 1))
+			return append(resourceDCNMVRFRead(ctx, d, m), deployWarning("VRF record is updated but not deployed yet", fmt.Errorf("Error while attachment : %s", err)))
+		}
+
+		// VRF Deployment, with deploy=false it is left to dcnm_deployment
+		if d.Get("deploy").(bool) {
+			err = deployVRF(dcnmClient, vrf.Fabric, vrf.Name)
 			if err != nil {
 				d.Set("deploy", false)
+				return append(resourceDCNMVRFRead(ctx, d, m), deployWarning("VRF record is updated but not deployed yet", err))
 			}
-			time.Sleep(10 * time.Second)
 
-		} else {
-			d.Set("deploy", false)
-			d.Set("attachments", make([]interface{}, 0, 1))
-			return fmt.Errorf("VRF record is not deployed yet. Either make deploy=false or provide attachments")
+			err = waitForDeployment(ctx, d.Timeout(schema.TimeoutUpdate), func() (bool, error) {
+				return checkVRFDeployDone(dcnmClient, vrf.Fabric, vrf.Name)
+			})
+			if err != nil {
+				return diag.FromErr(err)
+			}
 		}
 	}
 
 	log.Println("[DEBUG] End of Update method ", d.Id())
-	return resourceDCNMVRFRead(d, m)
+	return resourceDCNMVRFRead(ctx, d, m)
 }
 
-func resourceDCNMVRFRead(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMVRFRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Read method ", d.Id())
 
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	dn := d.Id()
 	fabricName := d.Get("fabric_name").(string)
 
 	cont, err := getRemoteVRF(dcnmClient, fabricName, dn)
 	if err != nil {
-		return err
+		if isNotFound(err) {
+			log.Printf("[WARN] VRF %s not found, removing from state", dn)
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
 	}
 
 	setVRFAttributes(d, cont)
 
-	flag, err := checkvrfDeploy(dcnmClient, fabricName, dn)
-	if err != nil {
-		d.Set("deploy", false)
-		return err
-	}
-	d.Set("deploy", flag)
-
+	// without attachments of its own the switches are attached through
+	// dcnm_vrf_attachment, which also tracks their deployment.
 	if attaches, ok := d.GetOk("attachments"); ok {
+		// with deploy=false the VRF is deployed by dcnm_deployment, its
+		// deployment is not tracked here.
+		if d.Get("deploy").(bool) {
+			flag, err := checkvrfDeploy(dcnmClient, fabricName, dn)
+			if err != nil {
+				d.Set("deploy", false)
+				return diag.FromErr(err)
+			}
+			d.Set("deploy", flag)
+		}
+
 		attachGet := make([]interface{}, 0, 1)
 
 		for _, val := range attaches.(*schema.Set).List() {
@@ -841,62 +765,53 @@ func resourceDCNMVRFRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceDCNMVRFDelete(d *schema.ResourceData, m interface{}) error {
+func resourceDCNMVRFDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("[DEBUG] Begining Delete method ", d.Id())
-	dcnmClient := m.(*client.Client)
+	dcnmClient := m.(*Client).withContext(ctx)
 
 	dn := d.Id()
 	fabricName := d.Get("fabric_name").(string)
 
-	if deploy, ok := d.GetOk("deploy"); ok && deploy.(bool) == true {
-		if _, ok := d.GetOk("attachments"); ok {
-			attachList := make([]map[string]interface{}, 0, 1)
-			for _, val := range d.Get("attachments").(*schema.Set).List() {
-				attachment := val.(map[string]interface{})
-
-				attachMap := make(map[string]interface{})
-
-				attachMap["fabric"] = fabricName
-				attachMap["vrfName"] = dn
-				attachMap["deployment"] = false
-				attachMap["serialNumber"] = attachment["serial_number"].(string)
-				if attachment["vlan_id"].(int) == 0 {
-					attachMap["vlan"] = d.Get("vlan_id").(int)
-				} else {
-					attachMap["vlan"] = attachment["vlan_id"].(int)
-				}
-
-				attachList = append(attachList, attachMap)
-			}
-
-			vrfAttach := models.NewVRFAttachment(dn, attachList)
-			durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/attachments", fabricName)
-			cont, err := dcnmClient.SaveForAttachment(durl, vrfAttach)
+	if _, ok := d.GetOk("attachments"); ok {
+		attachList := make([]map[string]interface{}, 0, 1)
+		for _, val := range d.Get("attachments").(*schema.Set).List() {
+			attachment := val.(map[string]interface{})
+			attachMap, err := getVRFAttachPayload(fabricName, dn, d.Get("vlan_id").(int), map[string]interface{}{
+				"serial_number": attachment["serial_number"],
+				"vlan_id":       attachment["vlan_id"],
+				"attach":        false,
+			})
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
+			attachList = append(attachList, attachMap)
+		}
 
-			// VRF Deployment
-			for _, v := range cont.Data().(map[string]interface{}) {
-				if v != "SUCCESS" && v != "SUCCESS Peer attach Reponse :  SUCCESS" {
-					return fmt.Errorf("failure at the time of detachment : %s", v)
-				}
-			}
-			vrfD := models.VRFDeploy{}
-			vrfD.Name = dn
-			durl = fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/deployments", fabricName)
-			_, err = dcnmClient.Save(durl, &vrfD)
+		err := attachVRF(dcnmClient, fabricName, dn, attachList)
+		if err != nil {
+			return diag.Errorf("failure at the time of detachment : %s", err)
+		}
+
+		// VRF Deployment
+		if d.Get("deploy").(bool) {
+			err = deployVRF(dcnmClient, fabricName, dn)
 			if err != nil {
 				d.Set("deploy", false)
+			} else {
+				err = waitForDeployment(ctx, d.Timeout(schema.TimeoutDelete), func() (bool, error) {
+					return checkVRFDeployDone(dcnmClient, fabricName, dn)
+				})
+				if err != nil {
+					return diag.FromErr(err)
+				}
 			}
-			time.Sleep(10 * time.Second)
 		}
 	}
 
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/%s", fabricName, dn)
 	_, err := dcnmClient.Delete(durl)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId("")
@@ -904,7 +819,83 @@ func resourceDCNMVRFDelete(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func checkvrfDeploy(client *client.Client, fabric, vrf string) (bool, error) {
+// getVRFAttachPayload builds the lanAttachList entry for a single switch, from
+// an attachment in the form of the attachments schema. vlan is used when the
+// attachment does not set its own vlan_id.
+func getVRFAttachPayload(fabric, vrf string, vlan int, attachment map[string]interface{}) (map[string]interface{}, error) {
+	attachMap := make(map[string]interface{})
+
+	attachMap["fabric"] = fabric
+	attachMap["vrfName"] = vrf
+	attachMap["deployment"] = attachment["attach"].(bool)
+	attachMap["serialNumber"] = attachment["serial_number"].(string)
+
+	if attachment["vlan_id"].(int) != 0 {
+		attachMap["vlan"] = attachment["vlan_id"].(int)
+	} else {
+		attachMap["vlan"] = vlan
+	}
+	if attachment["free_form_config"] != nil {
+		attachMap["freeformConfig"] = attachment["free_form_config"].(string)
+	}
+	if attachment["extension_values"] != nil {
+		attachMap["extensionValues"] = attachment["extension_values"].(string)
+	}
+
+	flag := false
+	instance := models.VRFInstance{}
+	if attachment["loopback_id"] != nil {
+		instance.LookbackID = attachment["loopback_id"].(int)
+		flag = true
+	}
+	if attachment["loopback_ipv4"] != nil {
+		instance.LoopbackIpv4 = attachment["loopback_ipv4"].(string)
+		flag = true
+	}
+	if attachment["loopback_ipv6"] != nil {
+		instance.LoopbackIpv6 = attachment["loopback_ipv6"].(string)
+		flag = true
+	}
+	if flag {
+		instStr, err := json.Marshal(instance)
+		if err != nil {
+			return nil, err
+		}
+		attachMap["instanceValues"] = string(instStr)
+	}
+
+	return attachMap, nil
+}
+
+// attachVRF saves the attach list of the VRF, only the switches listed are
+// attached or detached.
+func attachVRF(client *Client, fabric, vrf string, attachList []map[string]interface{}) error {
+	vrfAttach := models.NewVRFAttachment(vrf, attachList)
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/attachments", fabric)
+	cont, err := client.SaveForAttachment(durl, vrfAttach)
+	if err != nil {
+		return err
+	}
+
+	results, _ := cont.Data().(map[string]interface{})
+	for _, v := range results {
+		if v != "SUCCESS" && v != "SUCCESS Peer attach Reponse :  SUCCESS" {
+			return fmt.Errorf("%s", v)
+		}
+	}
+	return nil
+}
+
+// deployVRF deploys the pending attachments of the VRF.
+func deployVRF(client *Client, fabric, vrf string) error {
+	vrfD := models.VRFDeploy{}
+	vrfD.Name = vrf
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/deployments", fabric)
+	_, err := client.Save(durl, &vrfD)
+	return err
+}
+
+func checkvrfDeploy(client *Client, fabric, vrf string) (bool, error) {
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/attachments?vrf-names=%s", fabric, vrf)
 	cont, err := client.GetviaURL(durl)
 	if err != nil {
@@ -924,7 +915,28 @@ func checkvrfDeploy(client *client.Client, fabric, vrf string) (bool, error) {
 	return flag, nil
 }
 
-func getSwitchAttachStatus(client *client.Client, fabric, vrf, switchNum string) (bool, int, error) {
+// checkVRFDeployDone reports whether DCNM has finished deploying every
+// attachment of the VRF.
+func checkVRFDeployDone(client *Client, fabric, vrf string) (bool, error) {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/attachments?vrf-names=%s", fabric, vrf)
+	cont, err := client.GetviaURL(durl)
+	if err != nil {
+		return false, err
+	}
+
+	attachList := cont.Index(0).S("lanAttachList")
+	attaches, _ := attachList.Data().([]interface{})
+
+	for i := 0; i < len(attaches); i++ {
+		done, err := checkAttachState(attachList.Index(i))
+		if err != nil || !done {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func getSwitchAttachStatus(client *Client, fabric, vrf, switchNum string) (bool, int, error) {
 	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/attachments?vrf-names=%s", fabric, vrf)
 	cont, err := client.GetviaURL(durl)
 	if err != nil {
@@ -0,0 +1,380 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceDCNMVRFAttachment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDCNMVRFAttachmentCreate,
+		ReadContext:   resourceDCNMVRFAttachmentRead,
+		UpdateContext: resourceDCNMVRFAttachmentUpdate,
+		DeleteContext: resourceDCNMVRFAttachmentDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDCNMVRFAttachmentImporter,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"fabric_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"vrf_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"serial_number": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"vlan_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+
+			"free_form_config": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"extension_values": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"loopback_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+
+			"loopback_ipv4": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"loopback_ipv6": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"deploy": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+	}
+}
+
+// getRemoteVRFAttachment returns the lanAttachList entry of the switch, if it
+// is attached to the VRF.
+func getRemoteVRFAttachment(client *Client, fabric, vrf, serialNum string) (*container.Container, error) {
+	if _, err := getRemoteVRF(client, fabric, vrf); err != nil {
+		return nil, err
+	}
+
+	attach, err := getVRFSwitchAttachment(client, fabric, vrf, serialNum)
+	if err != nil {
+		return nil, err
+	}
+	if stripQuotes(attach.S("isLanAttached").String()) != "true" {
+		return nil, notFoundError(fmt.Sprintf("VRF %s is not attached to switch %s", vrf, serialNum))
+	}
+	return attach, nil
+}
+
+// getVRFSwitchAttachment returns the lanAttachList entry of the switch,
+// whether it is attached or not.
+func getVRFSwitchAttachment(client *Client, fabric, vrf, serialNum string) (*container.Container, error) {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/attachments?vrf-names=%s", fabric, vrf)
+	cont, err := client.GetviaURL(durl)
+	if err != nil {
+		return nil, err
+	}
+
+	attachList := cont.Index(0).S("lanAttachList")
+	attaches, _ := attachList.Data().([]interface{})
+	for i := 0; i < len(attaches); i++ {
+		attach := attachList.Index(i)
+		if stripQuotes(attach.S("switchSerialNo").String()) == serialNum {
+			return attach, nil
+		}
+	}
+	return nil, notFoundError(fmt.Sprintf("VRF %s is not attached to switch %s", vrf, serialNum))
+}
+
+func setVRFAttachmentAttributes(d *schema.ResourceData, cont *container.Container) *schema.ResourceData {
+	if vlan, err := strconv.Atoi(stripQuotes(cont.S("vlanId").String())); err == nil {
+		d.Set("vlan_id", vlan)
+	}
+
+	if instance, err := cleanJsonString(stripQuotes(cont.S("instanceValues").String())); err == nil {
+		if instance.Exists("loopbackId") {
+			if loopback, err := strconv.Atoi(stripQuotes(instance.S("loopbackId").String())); err == nil {
+				d.Set("loopback_id", loopback)
+			}
+		}
+		if instance.Exists("loopbackIpAddress") {
+			d.Set("loopback_ipv4", stripQuotes(instance.S("loopbackIpAddress").String()))
+		}
+		if instance.Exists("loopbackIpV6Address") {
+			d.Set("loopback_ipv6", stripQuotes(instance.S("loopbackIpV6Address").String()))
+		}
+	}
+
+	if cont.Exists("freeformConfig") {
+		d.Set("free_form_config", stripQuotes(cont.S("freeformConfig").String()))
+	}
+	if cont.Exists("extensionValues") {
+		d.Set("extension_values", stripQuotes(cont.S("extensionValues").String()))
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s:%s", d.Get("fabric_name").(string), d.Get("vrf_name").(string), d.Get("serial_number").(string)))
+	return d
+}
+
+// getVRFAttachmentConfig returns the configured attachment in the form of the
+// attachments schema of dcnm_vrf, so the payload can be built the same way.
+func getVRFAttachmentConfig(d *schema.ResourceData, attach bool) map[string]interface{} {
+	attachment := map[string]interface{}{
+		"serial_number": d.Get("serial_number").(string),
+		"vlan_id":       d.Get("vlan_id").(int),
+		"attach":        attach,
+	}
+	if !attach {
+		return attachment
+	}
+
+	if freeForm, ok := d.GetOk("free_form_config"); ok {
+		attachment["free_form_config"] = freeForm.(string)
+	}
+	if extValues, ok := d.GetOk("extension_values"); ok {
+		attachment["extension_values"] = extValues.(string)
+	}
+	if loopback, ok := d.GetOk("loopback_id"); ok {
+		attachment["loopback_id"] = loopback.(int)
+	}
+	if ipv4, ok := d.GetOk("loopback_ipv4"); ok {
+		attachment["loopback_ipv4"] = ipv4.(string)
+	}
+	if ipv6, ok := d.GetOk("loopback_ipv6"); ok {
+		attachment["loopback_ipv6"] = ipv6.(string)
+	}
+	return attachment
+}
+
+// saveVRFAttachment attaches, or detaches, the switch without touching the
+// other attachments of the VRF.
+func saveVRFAttachment(ctx context.Context, d *schema.ResourceData, m interface{}, attach bool) error {
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	fabricName := d.Get("fabric_name").(string)
+	vrfName := d.Get("vrf_name").(string)
+
+	cont, err := getRemoteVRF(dcnmClient, fabricName, vrfName)
+	if err != nil {
+		return err
+	}
+
+	// switches without their own vlan_id use the vlan of the VRF
+	vlan := 0
+	if config, err := cleanJsonString(stripQuotes(cont.S("vrfTemplateConfig").String())); err == nil {
+		vlan, _ = strconv.Atoi(stripQuotes(config.S("vrfVlanId").String()))
+	}
+
+	attachMap, err := getVRFAttachPayload(fabricName, vrfName, vlan, getVRFAttachmentConfig(d, attach))
+	if err != nil {
+		return err
+	}
+
+	err = attachVRF(dcnmClient, fabricName, vrfName, []map[string]interface{}{attachMap})
+	if err != nil {
+		return fmt.Errorf("Error while attachment : %s", err)
+	}
+	return nil
+}
+
+// deployVRFAttachment deploys the attachment when deploy is set. Failures to
+// start the deployment are returned as a warning, as the attachment itself is
+// already saved.
+func deployVRFAttachment(ctx context.Context, d *schema.ResourceData, m interface{}, summary string, timeout time.Duration) diag.Diagnostics {
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	if !d.Get("deploy").(bool) {
+		return nil
+	}
+
+	fabricName := d.Get("fabric_name").(string)
+	vrfName := d.Get("vrf_name").(string)
+	serialNum := d.Get("serial_number").(string)
+
+	err := deployVRFSwitch(dcnmClient, fabricName, vrfName, serialNum)
+	if err != nil {
+		d.Set("deploy", false)
+		return diag.Diagnostics{deployWarning(summary, err)}
+	}
+
+	err = waitForDeployment(ctx, timeout, func() (bool, error) {
+		return checkVRFAttachmentDeployDone(dcnmClient, fabricName, vrfName, serialNum)
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// deployVRFSwitch deploys the VRF on the switch only, the pending
+// attachments of the other switches are left as they are.
+func deployVRFSwitch(client *Client, fabric, vrf, serialNum string) error {
+	durl := fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/deploy", fabric)
+	_, err := client.Save(durl, deployPayload{serialNum: vrf})
+	return err
+}
+
+// checkVRFAttachmentDeployDone reports whether DCNM has finished deploying
+// the attachment of the switch.
+func checkVRFAttachmentDeployDone(client *Client, fabric, vrf, serialNum string) (bool, error) {
+	attach, err := getVRFSwitchAttachment(client, fabric, vrf, serialNum)
+	if err != nil {
+		if isNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return checkAttachState(attach)
+}
+
+func resourceDCNMVRFAttachmentImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	log.Println("[DEBUG] Begining Importer ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	importInfo := strings.Split(d.Id(), ":")
+	if len(importInfo) != 3 {
+		return nil, fmt.Errorf("not getting enough arguments for the import operation")
+	}
+
+	cont, err := getRemoteVRFAttachment(dcnmClient, importInfo[0], importInfo[1], importInfo[2])
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("fabric_name", importInfo[0])
+	d.Set("vrf_name", importInfo[1])
+	d.Set("serial_number", importInfo[2])
+	d.Set("deploy", stripQuotes(cont.S("lanAttachState").String()) == "DEPLOYED")
+	stateImport := setVRFAttachmentAttributes(d, cont)
+
+	log.Println("[DEBUG] End of Importer ", d.Id())
+	return []*schema.ResourceData{stateImport}, nil
+}
+
+func resourceDCNMVRFAttachmentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Create method ")
+
+	err := saveVRFAttachment(ctx, d, m, true)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(fmt.Sprintf("%s:%s:%s", d.Get("fabric_name").(string), d.Get("vrf_name").(string), d.Get("serial_number").(string)))
+
+	diags := deployVRFAttachment(ctx, d, m, "VRF attachment is created but not deployed yet", d.Timeout(schema.TimeoutCreate))
+	if diags.HasError() {
+		return diags
+	}
+
+	log.Println("[DEBUG] End of Create method ", d.Id())
+	return append(resourceDCNMVRFAttachmentRead(ctx, d, m), diags...)
+}
+
+func resourceDCNMVRFAttachmentUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Update method ", d.Id())
+
+	err := saveVRFAttachment(ctx, d, m, true)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	diags := deployVRFAttachment(ctx, d, m, "VRF attachment is updated but not deployed yet", d.Timeout(schema.TimeoutUpdate))
+	if diags.HasError() {
+		return diags
+	}
+
+	log.Println("[DEBUG] End of Update method ", d.Id())
+	return append(resourceDCNMVRFAttachmentRead(ctx, d, m), diags...)
+}
+
+func resourceDCNMVRFAttachmentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Read method ", d.Id())
+
+	dcnmClient := m.(*Client).withContext(ctx)
+
+	cont, err := getRemoteVRFAttachment(dcnmClient, d.Get("fabric_name").(string), d.Get("vrf_name").(string), d.Get("serial_number").(string))
+	if err != nil {
+		if isNotFound(err) {
+			log.Printf("[WARN] VRF attachment %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	setVRFAttachmentAttributes(d, cont)
+
+	// drift is only reported for attachments expected to be deployed, a
+	// later deploy of the VRF must not show a diff for the others.
+	if d.Get("deploy").(bool) {
+		d.Set("deploy", stripQuotes(cont.S("lanAttachState").String()) == "DEPLOYED")
+	}
+
+	log.Println("[DEBUG] End of Read method ", d.Id())
+	return nil
+}
+
+func resourceDCNMVRFAttachmentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] Begining Delete method ", d.Id())
+
+	err := saveVRFAttachment(ctx, d, m, false)
+	if err != nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	diags := deployVRFAttachment(ctx, d, m, "VRF attachment is removed but not undeployed yet", d.Timeout(schema.TimeoutDelete))
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId("")
+	log.Println("[DEBUG] End of Delete method ")
+	return diags
+}
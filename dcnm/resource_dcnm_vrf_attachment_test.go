@@ -0,0 +1,190 @@
+package dcnm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+var providerfVrfAttach *schema.Provider
+
+func TestAccDCNMVRFAttachment_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactoriesInternal(&providerfVrfAttach),
+		CheckDestroy:      testAccCheckDCNMVRFAttachmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckDCNMVRFAttachmentConfig_basic(2300),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDCNMVRFAttachmentExists("dcnm_vrf_attachment.test"),
+					resource.TestCheckResourceAttr("dcnm_vrf_attachment.test", "vlan_id", "2300"),
+				),
+			},
+			{
+				Config: testAccCheckDCNMVRFAttachmentConfig_basic(2301),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDCNMVRFAttachmentExists("dcnm_vrf_attachment.test"),
+					resource.TestCheckResourceAttr("dcnm_vrf_attachment.test", "vlan_id", "2301"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDCNMVRFAttachmentConfig_basic(vlan int) string {
+	return fmt.Sprintf(`
+	resource "dcnm_vrf" "test" {
+		fabric_name = "fab2"
+		name        = "tf_vrf_attach"
+		vlan_id     = 2002
+		deploy      = false
+	}
+
+	resource "dcnm_vrf_attachment" "test" {
+		fabric_name   = dcnm_vrf.test.fabric_name
+		vrf_name      = dcnm_vrf.test.name
+		serial_number = "9ZGMF8CBZK5"
+		vlan_id       = %d
+		loopback_id   = 70
+		loopback_ipv4 = "1.2.3.4"
+	}
+	`, vlan)
+}
+
+func testAccCheckDCNMVRFAttachmentExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+
+		if !ok {
+			return fmt.Errorf("VRF attachment %s not found", name)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No VRF attachment dn was set")
+		}
+
+		dcnmClient := (*providerfVrfAttach).Meta().(*Client)
+
+		attrs := rs.Primary.Attributes
+		_, err := getRemoteVRFAttachment(dcnmClient, attrs["fabric_name"], attrs["vrf_name"], attrs["serial_number"])
+		return err
+	}
+}
+
+func testAccCheckDCNMVRFAttachmentDestroy(s *terraform.State) error {
+	dcnmClient := (*providerfVrfAttach).Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type == "dcnm_vrf_attachment" {
+			attrs := rs.Primary.Attributes
+			_, err := getRemoteVRFAttachment(dcnmClient, attrs["fabric_name"], attrs["vrf_name"], attrs["serial_number"])
+			if err == nil {
+				return fmt.Errorf("VRF attachment still exists")
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestDCNMVRFAttachment_MockCRUD(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	rVRF := resourceDCNMVRF()
+	r := resourceDCNMVRFAttachment()
+
+	rawVRF := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_vrf_attach",
+		"vlan_id":     2110,
+		"deploy":      false,
+	}
+	vrfState := testMockApply(t, rVRF, nil, rawVRF, dcnmClient)
+
+	serial1 := testMockSerial(t, dcnmClient, "leaf1")
+	serial2 := testMockSerial(t, dcnmClient, "leaf2")
+
+	raw1 := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"vrf_name":      "mock_vrf_attach",
+		"serial_number": serial1,
+		"loopback_id":   70,
+		"loopback_ipv4": "1.2.3.4",
+
+		"free_form_config": "interface loopback70",
+	}
+	raw2 := map[string]interface{}{
+		"fabric_name":   "fab1",
+		"vrf_name":      "mock_vrf_attach",
+		"serial_number": serial2,
+		"vlan_id":       2311,
+		"deploy":        false,
+	}
+
+	state1 := testMockApply(t, r, nil, raw1, dcnmClient)
+	testMockCheckAttr(t, state1, "id", fmt.Sprintf("fab1:mock_vrf_attach:%s", serial1))
+	testMockCheckAttr(t, state1, "vlan_id", "2110")
+	testMockCheckAttr(t, state1, "loopback_id", "70")
+	testMockCheckAttr(t, state1, "deploy", "true")
+	state1 = testMockPlanEmpty(t, r, state1, raw1, dcnmClient)
+
+	state2 := testMockApply(t, r, nil, raw2, dcnmClient)
+	testMockCheckAttr(t, state2, "vlan_id", "2311")
+	testMockCheckAttr(t, state2, "deploy", "false")
+	state2 = testMockPlanEmpty(t, r, state2, raw2, dcnmClient)
+
+	// the VRF has no attachments of its own, so it must not pick up the
+	// deployment done by the attachment resources.
+	testMockPlanEmpty(t, rVRF, vrfState, rawVRF, dcnmClient)
+
+	raw1["vlan_id"] = 2312
+	state1 = testMockApply(t, r, state1, raw1, dcnmClient)
+	testMockCheckAttr(t, state1, "vlan_id", "2312")
+	state1 = testMockPlanEmpty(t, r, state1, raw1, dcnmClient)
+
+	// only the switch of the attachment is deployed.
+	attach2, err := getRemoteVRFAttachment(dcnmClient, "fab1", "mock_vrf_attach", serial2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := stripQuotes(attach2.S("lanAttachState").String()); state == "DEPLOYED" {
+		t.Fatalf("expected VRF attachment with deploy false to stay undeployed, got : %s", state)
+	}
+
+	// drift of the freeform config is reported.
+	testMockServer.outOfBand(func() {
+		testMockServer.vrfAttach["fab1/mock_vrf_attach"][serial1]["freeformConfig"] = "interface loopback71"
+	})
+	state1 = testMockRefresh(t, r, state1, dcnmClient)
+	testMockCheckAttr(t, state1, "free_form_config", "interface loopback71")
+	state1 = testMockApply(t, r, state1, raw1, dcnmClient)
+	testMockCheckAttr(t, state1, "free_form_config", "interface loopback70")
+
+	imported := testMockImport(t, r, fmt.Sprintf("fab1:mock_vrf_attach:%s", serial1), dcnmClient)
+	testMockCheckAttr(t, imported, "vlan_id", "2312")
+	testMockCheckAttr(t, imported, "loopback_ipv4", "1.2.3.4")
+	testMockCheckAttr(t, imported, "free_form_config", "interface loopback70")
+	testMockCheckAttr(t, imported, "deploy", "true")
+
+	testMockDestroy(t, r, state1, dcnmClient)
+	if _, err := getRemoteVRFAttachment(dcnmClient, "fab1", "mock_vrf_attach", serial1); !isNotFound(err) {
+		t.Fatalf("expected VRF attachment to be removed, got : %v", err)
+	}
+	if _, err := getRemoteVRFAttachment(dcnmClient, "fab1", "mock_vrf_attach", serial2); err != nil {
+		t.Fatalf("expected other VRF attachment to be kept, got : %s", err)
+	}
+
+	testMockDestroy(t, r, state2, dcnmClient)
+	testMockDestroy(t, rVRF, vrfState, dcnmClient)
+	testMockRefreshGone(t, r, state2, dcnmClient)
+
+	_, err = r.Importer.StateContext(context.Background(), r.Data(&terraform.InstanceState{ID: "fab1:mock_vrf_attach"}), dcnmClient)
+	if err == nil || !strings.Contains(err.Error(), "not getting enough arguments") {
+		t.Fatalf("expected import id error, got : %v", err)
+	}
+}
@@ -1,12 +1,13 @@
 package dcnm
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"testing"
 
-	"github.com/ciscoecosystem/dcnm-go-client/client"
 	"github.com/ciscoecosystem/dcnm-go-client/models"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -94,7 +95,7 @@ func testAccCheckDCNMVRFExists(name string, vrf *models.VRF, profile *models.VRF
 			return fmt.Errorf("No VRF dn was set")
 		}
 
-		dcnmClient := (*providerfVrf).Meta().(*client.Client)
+		dcnmClient := (*providerfVrf).Meta().(*Client)
 
 		cont, err := dcnmClient.GetviaURL(fmt.Sprintf("/rest/top-down/fabrics/%s/vrfs/%s", "fab2", rs.Primary.ID))
 		if err != nil {
@@ -134,7 +135,7 @@ func testAccCheckDCNMVRFExists(name string, vrf *models.VRF, profile *models.VRF
 }
 
 func testAccCheckDCNMVRFDestroy(s *terraform.State) error {
-	dcnmClient := (*providerfVrf).Meta().(*client.Client)
+	dcnmClient := (*providerfVrf).Meta().(*Client)
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type == "dcnm_vrf" {
@@ -172,3 +173,207 @@ func testAccCheckDCNMVRFAttributes(desc string, vrf *models.VRF, profile *models
 		return nil
 	}
 }
+
+func TestDCNMVRF_MockCRUD(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMVRF()
+
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_vrf",
+		"vlan_id":     2100,
+		"description": "first",
+		"deploy":      false,
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "id", "mock_vrf")
+	testMockCheckAttr(t, state, "vlan_id", "2100")
+	testMockCheckAttr(t, state, "description", "first")
+	testMockCheckAttr(t, state, "deploy", "false")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["description"] = "second"
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "description", "second")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	imported := testMockImport(t, r, "fab1:mock_vrf", dcnmClient)
+	testMockCheckAttr(t, imported, "fabric_name", "fab1")
+	testMockCheckAttr(t, imported, "description", "second")
+
+	testMockDestroy(t, r, state, dcnmClient)
+	if _, err := getRemoteVRF(dcnmClient, "fab1", "mock_vrf"); err == nil {
+		t.Fatalf("VRF still exists")
+	}
+}
+
+func TestDCNMVRF_MockDeploy(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMVRF()
+
+	serial := testMockSerial(t, dcnmClient, "leaf1")
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_vrf_deploy",
+		"vlan_id":     2101,
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"serial_number": serial,
+				"vlan_id":       2101,
+			},
+		},
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	testMockCheckAttr(t, state, "deploy", "true")
+	testMockCheckAttr(t, state, "attachments.#", "1")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	testMockDestroy(t, r, state, dcnmClient)
+	if _, err := getRemoteVRF(dcnmClient, "fab1", "mock_vrf_deploy"); err == nil {
+		t.Fatalf("VRF still exists")
+	}
+}
+
+func TestDCNMVRF_MockAttachWithoutDeploy(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMVRF()
+
+	serial := testMockSerial(t, dcnmClient, "leaf1")
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_vrf_nodeploy",
+		"vlan_id":     2104,
+		"deploy":      false,
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"serial_number": serial,
+				"vlan_id":       2104,
+			},
+		},
+	}
+
+	// the switches are attached, the deployment is left to dcnm_deployment.
+	deploys := testMockServer.callCount("POST", "/rest/top-down/fabrics/fab1/vrfs/deployments")
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	if got := testMockServer.callCount("POST", "/rest/top-down/fabrics/fab1/vrfs/deployments") - deploys; got != 0 {
+		t.Fatalf("expected no deployment request, got %d", got)
+	}
+	attach, err := getRemoteVRFAttachment(dcnmClient, "fab1", "mock_vrf_nodeploy", serial)
+	if err != nil {
+		t.Fatalf("expected switch to be attached, got : %s", err)
+	}
+	if lanState := stripQuotes(attach.S("lanAttachState").String()); lanState == "DEPLOYED" {
+		t.Fatalf("expected attachment not to be deployed, got : %s", lanState)
+	}
+	testMockCheckAttr(t, state, "deploy", "false")
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	raw["deploy"] = true
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	testMockCheckAttr(t, state, "deploy", "true")
+
+	// turning deploy off hands the deployment over, it does not undeploy.
+	raw["deploy"] = false
+	state = testMockApply(t, r, state, raw, dcnmClient)
+	attach, err = getRemoteVRFAttachment(dcnmClient, "fab1", "mock_vrf_nodeploy", serial)
+	if err != nil {
+		t.Fatalf("expected switch to stay attached, got : %s", err)
+	}
+	if lanState := stripQuotes(attach.S("lanAttachState").String()); lanState != "DEPLOYED" {
+		t.Fatalf("expected attachment to stay deployed, got : %s", lanState)
+	}
+	state = testMockPlanEmpty(t, r, state, raw, dcnmClient)
+
+	testMockDestroy(t, r, state, dcnmClient)
+}
+
+func TestDCNMVRF_MockDeployWarning(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMVRF()
+
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_vrf_warning",
+		"vlan_id":     2102,
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"serial_number": "UNKNOWN",
+				"vlan_id":       2102,
+			},
+		},
+	}
+
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), dcnmClient)
+	if err != nil {
+		t.Fatalf("err : %s", err)
+	}
+
+	state, diags := r.Apply(context.Background(), nil, diff, dcnmClient)
+	if diags.HasError() {
+		t.Fatalf("expected only warnings, got : %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a single warning, got : %v", diags)
+	}
+	testMockCheckAttr(t, state, "id", "mock_vrf_warning")
+	testMockCheckAttr(t, state, "deploy", "false")
+
+	testMockDestroy(t, r, state, dcnmClient)
+}
+
+func TestDCNMVRF_MockDeletedOutOfBand(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMVRF()
+
+	raw := map[string]interface{}{
+		"fabric_name": "fab1",
+		"name":        "mock_vrf_gone",
+		"vlan_id":     2103,
+		"deploy":      false,
+	}
+
+	state := testMockApply(t, r, nil, raw, dcnmClient)
+	if _, err := dcnmClient.Delete("/rest/top-down/fabrics/fab1/vrfs/mock_vrf_gone"); err != nil {
+		t.Fatalf("err : %s", err)
+	}
+	testMockRefreshGone(t, r, state, dcnmClient)
+}
+
+func TestDCNMVRF_MockListDataSource(t *testing.T) {
+	dcnmClient := testMockClient(t)
+	r := resourceDCNMVRF()
+
+	states := make([]*terraform.InstanceState, 0, 2)
+	for i, name := range []string{"mock_list_vrf1", "mock_list_vrf2"} {
+		raw := map[string]interface{}{
+			"fabric_name": "fab1",
+			"name":        name,
+			"vlan_id":     2110 + i,
+			"description": name,
+			"deploy":      false,
+		}
+		states = append(states, testMockApply(t, r, nil, raw, dcnmClient))
+	}
+
+	data := testMockReadData(t, datasourceDCNMVRFs(), map[string]interface{}{
+		"fabric_name": "fab1",
+		"name_regex":  "^mock_list_vrf",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "vrfs.#", "2")
+	testMockCheckAttr(t, data, "vrfs.0.name", "mock_list_vrf1")
+	testMockCheckAttr(t, data, "vrfs.0.vlan_id", "2110")
+	testMockCheckAttr(t, data, "vrfs.1.name", "mock_list_vrf2")
+	testMockCheckAttr(t, data, "vrfs.1.description", "mock_list_vrf2")
+
+	data = testMockReadData(t, datasourceDCNMVRFs(), map[string]interface{}{
+		"fabric_name": "fab1",
+		"name_regex":  "^mock_list_vrf2$",
+	}, dcnmClient)
+	testMockCheckAttr(t, data, "vrfs.#", "1")
+
+	for _, state := range states {
+		testMockDestroy(t, r, state, dcnmClient)
+	}
+}
@@ -0,0 +1,64 @@
+package dcnm
+
+import (
+	"sync"
+	"time"
+)
+
+// throttle caps the requests sent to the controller, both in number of
+// requests in flight and in rate. A nil or zero throttle lets every request
+// through.
+type throttle struct {
+	// slots holds one value per request in flight, it is nil without a
+	// concurrency cap.
+	slots chan struct{}
+
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newThrottle(maxConcurrent int, perSecond float64) *throttle {
+	t := &throttle{}
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+	if perSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return t
+}
+
+// acquire waits for the turn of a request, which must call release once its
+// response is read.
+func (t *throttle) acquire() {
+	if t == nil {
+		return
+	}
+	if t.slots != nil {
+		t.slots <- struct{}{}
+	}
+
+	if t.interval == 0 {
+		return
+	}
+
+	// the requests are spaced by the same interval, each one reserving the
+	// time after the previous one.
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	wait := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mu.Unlock()
+
+	time.Sleep(wait)
+}
+
+func (t *throttle) release() {
+	if t != nil && t.slots != nil {
+		<-t.slots
+	}
+}
@@ -0,0 +1,134 @@
+package dcnm
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tokenCache keeps the login tokens in a file, so the provider instances of a
+// run share one session instead of each logging in.
+type tokenCache struct {
+	path string
+
+	// key identifies the controller and the credentials of the token, without
+	// storing the password.
+	key string
+}
+
+type cachedToken struct {
+	Token  string    `json:"token"`
+	Expiry time.Time `json:"expiry"`
+}
+
+func newTokenCache(path string, c Config) *tokenCache {
+	sum := sha256.Sum256([]byte(strings.Join([]string{c.URL, c.Platform, c.Domain, c.Username, c.Password}, "\x00")))
+	return &tokenCache{
+		path: path,
+		key:  hex.EncodeToString(sum[:]),
+	}
+}
+
+// load returns the tokens of the cache file. A missing or unreadable file is
+// an empty cache.
+func (tc *tokenCache) load() map[string]cachedToken {
+	tokens := make(map[string]cachedToken)
+
+	data, err := ioutil.ReadFile(tc.path)
+	if err != nil {
+		return tokens
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		log.Printf("[WARN] Ignoring token cache %s : %s", tc.path, err)
+	}
+	return tokens
+}
+
+// save writes the tokens which are still valid, through a temporary file so
+// other provider instances never read a partial cache.
+func (tc *tokenCache) save(tokens map[string]cachedToken) {
+	for key, token := range tokens {
+		if time.Now().After(token.Expiry) {
+			delete(tokens, key)
+		}
+	}
+
+	data, err := json.Marshal(tokens)
+	if err == nil {
+		var tmp *os.File
+		tmp, err = ioutil.TempFile(filepath.Dir(tc.path), filepath.Base(tc.path))
+		if err == nil {
+			_, err = tmp.Write(data)
+			if closeErr := tmp.Close(); err == nil {
+				err = closeErr
+			}
+			if err == nil {
+				err = os.Rename(tmp.Name(), tc.path)
+			}
+			if err != nil {
+				os.Remove(tmp.Name())
+			}
+		}
+	}
+
+	if err != nil {
+		log.Printf("[WARN] Unable to write token cache %s : %s", tc.path, err)
+	}
+}
+
+func (tc *tokenCache) get() (cachedToken, bool) {
+	token, ok := tc.load()[tc.key]
+	return token, ok
+}
+
+func (tc *tokenCache) put(token cachedToken) {
+	tc.update(func(tokens map[string]cachedToken) {
+		tokens[tc.key] = token
+	})
+}
+
+func (tc *tokenCache) remove() {
+	tc.update(func(tokens map[string]cachedToken) {
+		delete(tokens, tc.key)
+	})
+}
+
+// update changes the tokens of the cache file while holding a lock on
+// path.lock, so the provider instances writing at the same time do not drop
+// each other's tokens. The cache is still written when the lock is not
+// available, as it is only an optimisation.
+func (tc *tokenCache) update(change func(tokens map[string]cachedToken)) {
+	unlock, err := lockFile(tc.path + ".lock")
+	if err != nil {
+		log.Printf("[WARN] Unable to lock token cache %s : %s", tc.path, err)
+	} else {
+		defer unlock()
+	}
+
+	tokens := tc.load()
+	change(tokens)
+	tc.save(tokens)
+}
+
+// tokenExpiry returns the expiry of a JWT token, as NDFC tokens do not last
+// for the requested expiry. Other tokens expire after fallback.
+func tokenExpiry(token string, fallback time.Duration) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) == 3 {
+		var claims struct {
+			Exp int64 `json:"exp"`
+		}
+		data, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err == nil && json.Unmarshal(data, &claims) == nil && claims.Exp > 0 {
+			return time.Unix(claims.Exp, 0)
+		}
+	}
+	return time.Now().Add(fallback)
+}
@@ -1,13 +1,59 @@
 package dcnm
 
 import (
+	"context"
+	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/ciscoecosystem/dcnm-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// deployWarning reports a partially applied change, where the object was saved
+// in DCNM but could not be deployed.
+func deployWarning(summary string, err error) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  summary,
+		Detail:   err.Error(),
+	}
+}
+
+// deployPollInterval is the minimum time between two deployment status checks.
+var deployPollInterval = 5 * time.Second
+
+// waitForDeployment polls check until it reports the deployment as complete,
+// check fails, the timeout expires or ctx is cancelled.
+func waitForDeployment(ctx context.Context, timeout time.Duration, check func() (bool, error)) error {
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"pending"},
+		Target:     []string{"done"},
+		Timeout:    timeout,
+		MinTimeout: deployPollInterval,
+		Refresh: func() (interface{}, string, error) {
+			done, err := check()
+			if err != nil {
+				return nil, "", err
+			}
+			if done {
+				return done, "done", nil
+			}
+			return done, "pending", nil
+		},
+	}
+
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("error while waiting for deployment : %s", err)
+	}
+	return nil
+}
+
 func stripQuotes(word string) string {
 	if strings.HasPrefix(word, "\"") && strings.HasSuffix(word, "\"") {
 		return strings.TrimSuffix(strings.TrimPrefix(word, "\""), "\"")
@@ -58,6 +104,24 @@ func interfaceToStrList(data interface{}) []string {
 	return strList
 }
 
+func stringInSlice(str string, list []string) bool {
+	for _, val := range list {
+		if val == str {
+			return true
+		}
+	}
+	return false
+}
+
+// nameFilter returns the name_regex of the list data sources, which matches
+// every name when it is not set.
+func nameFilter(d *schema.ResourceData) *regexp.Regexp {
+	if expr, ok := d.GetOk("name_regex"); ok {
+		return regexp.MustCompile(expr.(string))
+	}
+	return regexp.MustCompile("")
+}
+
 func compareStrLists(first, second []string) bool {
 	sort.Strings(first)
 	sort.Strings(second)
@@ -0,0 +1,51 @@
+provider "dcnm" {
+  username = ""
+  password = ""
+  url      = ""
+  # expiry   = 900000
+}
+
+data "dcnm_inventory" "inv" {
+  fabric_name = "fab2"
+  switch_name = "leaf1"
+}
+
+resource "dcnm_network" "first" {
+  fabric_name = "fab2"
+  name        = "first"
+  vrf_name    = "VRF1012"
+  vlan_id     = 2300
+  deploy      = false
+}
+
+resource "dcnm_network" "second" {
+  fabric_name = "fab2"
+  name        = "second"
+  vrf_name    = "VRF1012"
+  vlan_id     = 2301
+  deploy      = false
+}
+
+resource "dcnm_network_attachment" "first" {
+  fabric_name   = dcnm_network.first.fabric_name
+  network_name  = dcnm_network.first.name
+  serial_number = data.dcnm_inventory.inv.serial_number
+  switch_ports  = ["Ethernet1/5"]
+  deploy        = false
+}
+
+resource "dcnm_network_attachment" "second" {
+  fabric_name   = dcnm_network.second.fabric_name
+  network_name  = dcnm_network.second.name
+  serial_number = data.dcnm_inventory.inv.serial_number
+  switch_ports  = ["Ethernet1/6"]
+  deploy        = false
+}
+
+resource "dcnm_deployment" "fab2" {
+  fabric_name = "fab2"
+  networks = [
+    dcnm_network_attachment.first.network_name,
+    dcnm_network_attachment.second.network_name,
+  ]
+}
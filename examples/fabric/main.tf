@@ -0,0 +1,29 @@
+provider "dcnm" {
+  username = ""
+  password = ""
+  url      = ""
+  # expiry   = 900000
+}
+
+resource "dcnm_fabric" "first" {
+  name                   = "fab1"
+  bgp_asn                = "65001"
+  replication_mode       = "Multicast"
+  underlay_routing       = "ospf"
+  loopback0_ip_range     = "10.2.0.0/22"
+  loopback1_ip_range     = "10.3.0.0/22"
+  subnet_range           = "10.4.0.0/16"
+  anycast_rp_ip_range    = "10.254.254.0/24"
+  multicast_group_subnet = "239.1.1.0/25"
+  nv_pairs = {
+    VRF_VLAN_RANGE = "2000-2299"
+  }
+}
+
+data "dcnm_fabric" "check" {
+  name = dcnm_fabric.first.name
+}
+
+output "fabric_asn" {
+  value = data.dcnm_fabric.check.bgp_asn
+}
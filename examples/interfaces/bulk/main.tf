@@ -0,0 +1,28 @@
+provider "dcnm" {
+  username = ""
+  password = ""
+  url      = ""
+  # expiry   = 900000
+}
+
+variable "leaf1_ports" {
+  default = {
+    "Ethernet1/1" = { vlan = "10", description = "host a" }
+    "Ethernet1/2" = { vlan = "20", description = "host b" }
+  }
+}
+
+resource "dcnm_interfaces" "leaf1" {
+  fabric_name = "fab2"
+
+  dynamic "interface" {
+    for_each = var.leaf1_ports
+    content {
+      switch_name  = "leaf1"
+      name         = interface.key
+      policy       = "int_access_host_11_1"
+      access_vlans = interface.value.vlan
+      description  = interface.value.description
+    }
+  }
+}
@@ -0,0 +1,30 @@
+provider "dcnm" {
+  username = ""
+  password = ""
+  url      = ""
+  # expiry   = 900000
+}
+
+data "dcnm_inventory" "inv" {
+  fabric_name = "example"
+  switch_name = "example"
+}
+
+resource "dcnm_network" "first" {
+  fabric_name = "fab2"
+  name        = "first"
+  vrf_name    = "VRF1012"
+  vlan_id     = 2300
+  deploy      = false
+}
+
+resource "dcnm_network_attachment" "first" {
+  fabric_name   = dcnm_network.first.fabric_name
+  network_name  = dcnm_network.first.name
+  serial_number = data.dcnm_inventory.inv.serial_number
+  vlan_id       = 2300
+  switch_ports = [
+    "Ethernet1/5",
+    "Ethernet1/6"
+  ]
+}
@@ -19,4 +19,13 @@ resource "dcnm_rest" "first" {
     "vrf": "MyVRF"
   }
  EOF 
-}
\ No newline at end of file
+}
+
+data "dcnm_rest" "bgp_asn" {
+  path   = "/rest/control/fabrics/fab2"
+  filter = "/nvPairs/BGP_AS"
+}
+
+output "bgp_asn" {
+  value = data.dcnm_rest.bgp_asn.flattened["value"]
+}
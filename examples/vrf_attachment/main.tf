@@ -40,3 +40,19 @@ resource "dcnm_vrf" "first" {
     loopback_ipv4 = "1.2.3.4"
   }
 }
+
+resource "dcnm_vrf" "second" {
+  fabric_name = "fab2"
+  name        = "second"
+  vlan_id     = 2003
+  deploy      = false
+}
+
+resource "dcnm_vrf_attachment" "second" {
+  fabric_name   = dcnm_vrf.second.fabric_name
+  vrf_name      = dcnm_vrf.second.name
+  serial_number = data.dcnm_inventory.inv.serial_number
+  vlan_id       = 2301
+  loopback_id   = 71
+  loopback_ipv4 = "1.2.3.5"
+}
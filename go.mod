@@ -5,4 +5,5 @@ go 1.15
 require (
 	github.com/ciscoecosystem/dcnm-go-client v0.0.31
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.3.0
+	golang.org/x/sys v0.0.0-20200523222454-059865788121
 )